@@ -0,0 +1,133 @@
+package main
+
+import (
+	"CLIMultiChat/internal/broadcast"
+	messengers "CLIMultiChat/internal/integrations"
+	"errors"
+	"fmt"
+	"os"
+
+	"github.com/spf13/cobra"
+)
+
+// Exit codes reported for broadcasts, so scripts can tell a complete outage
+// from a partial delivery.
+const (
+	exitFailure        = 1
+	exitTotalFailure   = 2
+	exitPartialFailure = 3
+)
+
+var (
+	workers         int
+	replyTo         string
+	threadBroadcast bool
+	attachments     []string
+	numberParts     bool
+	allowMentions   bool
+)
+
+// exitError carries the process exit code for an error returned by a command.
+type exitError struct {
+	code int
+	err  error
+}
+
+func (e *exitError) Error() string {
+	return e.err.Error()
+}
+
+func (e *exitError) Unwrap() error {
+	return e.err
+}
+
+func exitCode(err error) int {
+	var exitErr *exitError
+	if errors.As(err, &exitErr) {
+		return exitErr.code
+	}
+	return exitFailure
+}
+
+// broadcastError maps the broadcast results to the command error and exit code.
+func broadcastError(results []broadcast.Result) error {
+	failed := broadcast.Failed(results)
+
+	switch {
+	case failed == 0:
+		return nil
+	case failed == len(results):
+		return &exitError{exitTotalFailure, fmt.Errorf("не вдалося надіслати в жоден месенджер")}
+	default:
+		return &exitError{exitPartialFailure, fmt.Errorf("не вдалося надіслати у всі месенджери (%d з %d)", failed, len(results))}
+	}
+}
+
+// sendOne delivers message to a single destination and reports the outcome.
+func sendOne(cmd *cobra.Command, target broadcast.Target, message string) error {
+	opts, message, err := sendOptions(message)
+	if err != nil {
+		return err
+	}
+
+	cmd.SilenceUsage = true
+	if err := validateMessage(message, []broadcast.Target{target}, opts); err != nil {
+		return err
+	}
+
+	result := broadcast.Send([]broadcast.Target{target}, message, 1, opts...)[0]
+	printOutcome(result, "Повідомлення надіслано у %s")
+
+	return result.Err
+}
+
+// sendOptions builds the messenger send options from the command flags and
+// the message. A message with embed front-matter or --embed-* flags becomes
+// an embed option, and the returned message is then its Markdown fallback
+// for the platforms without embeds.
+func sendOptions(message string) ([]messengers.SendOption, string, error) {
+	var opts []messengers.SendOption
+
+	if replyTo != "" {
+		opts = append(opts, messengers.WithReplyTo(replyTo))
+	}
+	if threadBroadcast {
+		if replyTo == "" {
+			return nil, "", fmt.Errorf("--thread-broadcast requires --reply-to")
+		}
+		opts = append(opts, messengers.WithThreadBroadcast())
+	}
+
+	for _, path := range attachments {
+		info, err := os.Stat(path)
+		if err != nil {
+			return nil, "", fmt.Errorf("attachment: %w", err)
+		}
+		if info.IsDir() {
+			return nil, "", fmt.Errorf("attachment %s is a directory", path)
+		}
+	}
+	if len(attachments) > 0 {
+		opts = append(opts, messengers.WithAttachments(attachments...))
+	}
+
+	if numberParts {
+		opts = append(opts, messengers.WithNumberedParts())
+	}
+	// Piped input is usually logs or other tool output, where a stray
+	// @everyone must not ping a whole server.
+	if messageFromStdin && !allowMentions {
+		opts = append(opts, messengers.WithSafeMentions())
+	}
+
+	embed, err := messageEmbed(message)
+	if err != nil {
+		return nil, "", err
+	}
+	if embed != nil {
+		opts = append(opts, messengers.WithEmbed(embed))
+		message = embed.Markdown()
+	}
+
+	return opts, message, nil
+}
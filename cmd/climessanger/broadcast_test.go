@@ -0,0 +1,56 @@
+package main
+
+import (
+	"CLIMultiChat/internal/broadcast"
+	"errors"
+	"fmt"
+	"testing"
+)
+
+func TestBroadcastExitCodes(t *testing.T) {
+	ok := broadcast.Result{Platform: "Slack"}
+	failed := broadcast.Result{Platform: "Discord", Err: errors.New("rejected")}
+
+	tests := []struct {
+		name    string
+		results []broadcast.Result
+		want    int // 0 for no error
+	}{
+		{"all delivered", []broadcast.Result{ok, ok, ok}, 0},
+		{"none delivered", []broadcast.Result{failed, failed}, exitTotalFailure},
+		{"single failure", []broadcast.Result{failed}, exitTotalFailure},
+		{"some delivered", []broadcast.Result{ok, failed, ok}, exitPartialFailure},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := broadcastError(tt.results)
+			if tt.want == 0 {
+				if err != nil {
+					t.Errorf("broadcastError = %v, want nil", err)
+				}
+				return
+			}
+			if got := exitCode(err); got != tt.want {
+				t.Errorf("exit code = %d, want %d", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestExitCode(t *testing.T) {
+	tests := []struct {
+		err  error
+		want int
+	}{
+		{errors.New("bad flag"), exitFailure},
+		{&exitError{exitPartialFailure, errors.New("partial")}, exitPartialFailure},
+		{fmt.Errorf("wrapped: %w", &exitError{exitTotalFailure, errors.New("total")}), exitTotalFailure},
+	}
+
+	for _, tt := range tests {
+		if got := exitCode(tt.err); got != tt.want {
+			t.Errorf("exitCode(%v) = %d, want %d", tt.err, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,119 @@
+package main
+
+import (
+	"CLIMultiChat/internal/broadcast"
+	messengers "CLIMultiChat/internal/integrations"
+	"fmt"
+	"time"
+
+	"github.com/spf13/cobra"
+)
+
+// platform describes a per-platform subcommand: its name, display title, how
+// the channel argument is called in usage lines and how to build a target.
+type platform struct {
+	name       string
+	title      string
+	channelArg string
+	target     func(channel string) broadcast.Target
+}
+
+var platforms = []platform{
+	{"slack", "Slack", "канал", slackTarget},
+	{"telegram", "Telegram", "chat_id", telegramTarget},
+	{"discord", "Discord", "канал", discordTarget},
+}
+
+var editCmd = &cobra.Command{
+	Use:   "edit",
+	Short: "Редагувати повідомлення",
+	Long: `Замінити текст раніше надісланого повідомлення у Slack, Telegram або Discord.
+
+ID повідомлення - це значення, яке повертає send (ts у Slack, message ID у
+Telegram та Discord). Новий текст читається так само, як у send: аргументом,
+з файлу (--file) або зі stdin. Якщо stdin не є терміналом, текст береться з
+нього, а аргументи - це канал та ID:
+
+  echo "новий текст" | climessenger edit slack C0123 1700000000.000100`,
+}
+
+var deleteCmd = &cobra.Command{
+	Use:   "delete",
+	Short: "Видалити повідомлення",
+	Long:  `Видалити раніше надіслане повідомлення у Slack, Telegram або Discord.`,
+}
+
+func newEditCmd(p platform) *cobra.Command {
+	return &cobra.Command{
+		Use:   fmt.Sprintf("%s [%s] <id> [повідомлення]", p.name, p.channelArg),
+		Short: "В " + p.title,
+		Args:  cobra.RangeArgs(1, 3),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			rest, message, err := readMessage(args, 1)
+			if err != nil {
+				return err
+			}
+
+			channel, messageID, err := channelAndID(rest)
+			if err != nil {
+				return err
+			}
+
+			cmd.SilenceUsage = true
+			target := p.target(channel)
+			result := broadcast.Result{Platform: target.Platform, Channel: target.Channel, Err: target.Err}
+
+			if target.Err == nil {
+				start := time.Now()
+				result.Receipt, result.Err = target.Messenger.EditMessage(target.Channel, messageID, message)
+				result.Latency = time.Since(start)
+			}
+
+			printOutcome(result, "Повідомлення оновлено у %s")
+			return result.Err
+		},
+	}
+}
+
+func newDeleteCmd(p platform) *cobra.Command {
+	return &cobra.Command{
+		Use:   fmt.Sprintf("%s [%s] <id>", p.name, p.channelArg),
+		Short: "В " + p.title,
+		Args:  cobra.RangeArgs(1, 2),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			channel, messageID, err := channelAndID(args)
+			if err != nil {
+				return err
+			}
+
+			cmd.SilenceUsage = true
+			target := p.target(channel)
+			result := broadcast.Result{Platform: target.Platform, Channel: target.Channel, Err: target.Err}
+
+			if target.Err == nil {
+				start := time.Now()
+				result.Err = target.Messenger.DeleteMessage(target.Channel, messageID)
+				result.Latency = time.Since(start)
+			}
+			if result.Err == nil {
+				result.Receipt = &messengers.Receipt{Platform: target.Platform, Channel: target.Channel, MessageID: messageID}
+			}
+
+			printOutcome(result, "Повідомлення видалено у %s")
+			return result.Err
+		},
+	}
+}
+
+// channelAndID resolves the optional channel and the message ID of the edit
+// and delete commands.
+func channelAndID(args []string) (string, string, error) {
+	switch len(args) {
+	case 1:
+		return "", args[0], nil
+	case 2:
+		return args[0], args[1], nil
+	default:
+		return "", "", errTooManyArgs()
+	}
+}
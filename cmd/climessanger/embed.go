@@ -0,0 +1,203 @@
+package main
+
+import (
+	messengers "CLIMultiChat/internal/integrations"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"gopkg.in/yaml.v3"
+)
+
+// embedFlags holds the --embed-* flags of the send commands. They override
+// the values of the message's front-matter.
+var embedFlags struct {
+	enabled      bool
+	title        string
+	url          string
+	color        string
+	footer       string
+	timestamp    string
+	fields       []string
+	inlineFields []string
+}
+
+// embedSpec is the front-matter of a message sent as an embed:
+//
+//	---
+//	title: Release 1.4
+//	color: "#5865F2"
+//	fields:
+//	  - name: Version
+//	    value: 1.4.0
+//	    inline: true
+//	footer: CI
+//	timestamp: now
+//	---
+//	Markdown description...
+//
+// JSON works as well, since YAML is a superset of it.
+type embedSpec struct {
+	Title     string `yaml:"title"`
+	URL       string `yaml:"url"`
+	Color     string `yaml:"color"`
+	Footer    string `yaml:"footer"`
+	Timestamp string `yaml:"timestamp"`
+	Fields    []struct {
+		Name   string `yaml:"name"`
+		Value  string `yaml:"value"`
+		Inline bool   `yaml:"inline"`
+	} `yaml:"fields"`
+}
+
+// embedKeys are the keys that make a leading --- block front-matter rather
+// than a Markdown rule.
+var embedKeys = map[string]bool{
+	"title": true, "url": true, "color": true, "footer": true, "timestamp": true, "fields": true,
+}
+
+// splitFrontMatter separates a leading front-matter block from message. ok
+// is false when message does not start with one.
+func splitFrontMatter(message string) (spec embedSpec, body string, ok bool, err error) {
+	rest, found := strings.CutPrefix(message, "---\n")
+	if !found {
+		return embedSpec{}, message, false, nil
+	}
+
+	end := strings.Index(rest, "\n---\n")
+	head, body := "", ""
+	switch {
+	case end >= 0:
+		head, body = rest[:end], rest[end+len("\n---\n"):]
+	case strings.HasSuffix(rest, "\n---"):
+		head = strings.TrimSuffix(rest, "\n---")
+	default:
+		return embedSpec{}, message, false, nil
+	}
+
+	var keys map[string]any
+	if yaml.Unmarshal([]byte(head), &keys) != nil || !hasEmbedKey(keys) {
+		return embedSpec{}, message, false, nil
+	}
+	for key := range keys {
+		if !embedKeys[key] {
+			return embedSpec{}, "", false, fmt.Errorf("front-matter: unknown key %q", key)
+		}
+	}
+
+	if err := yaml.Unmarshal([]byte(head), &spec); err != nil {
+		return embedSpec{}, "", false, fmt.Errorf("front-matter: %w", err)
+	}
+
+	return spec, strings.TrimSpace(body), true, nil
+}
+
+func hasEmbedKey(keys map[string]any) bool {
+	for key := range keys {
+		if embedKeys[key] {
+			return true
+		}
+	}
+	return false
+}
+
+// messageEmbed builds the embed of message from its front-matter and the
+// --embed-* flags. It returns nil when neither asks for an embed.
+func messageEmbed(message string) (*messengers.Embed, error) {
+	spec, body, ok, err := splitFrontMatter(message)
+	if err != nil {
+		return nil, err
+	}
+
+	f := embedFlags
+	if !ok && !f.enabled && f.title == "" && f.url == "" && f.color == "" && f.footer == "" &&
+		f.timestamp == "" && len(f.fields) == 0 && len(f.inlineFields) == 0 {
+		return nil, nil
+	}
+
+	set := func(dst *string, flag string) {
+		if flag != "" {
+			*dst = flag
+		}
+	}
+	set(&spec.Title, f.title)
+	set(&spec.URL, f.url)
+	set(&spec.Color, f.color)
+	set(&spec.Footer, f.footer)
+	set(&spec.Timestamp, f.timestamp)
+
+	embed := &messengers.Embed{
+		Title:       spec.Title,
+		URL:         spec.URL,
+		Description: body,
+		Footer:      spec.Footer,
+	}
+
+	if embed.Color, err = parseColor(spec.Color); err != nil {
+		return nil, err
+	}
+	if embed.Timestamp, err = parseTimestamp(spec.Timestamp); err != nil {
+		return nil, err
+	}
+
+	for _, field := range spec.Fields {
+		embed.Fields = append(embed.Fields, messengers.EmbedField{Name: field.Name, Value: field.Value, Inline: field.Inline})
+	}
+	for _, flags := range []struct {
+		values []string
+		inline bool
+	}{{f.fields, false}, {f.inlineFields, true}} {
+		for _, field := range flags.values {
+			name, value, ok := strings.Cut(field, "=")
+			if !ok || name == "" {
+				return nil, fmt.Errorf("invalid embed field %q (expected name=value)", field)
+			}
+			embed.Fields = append(embed.Fields, messengers.EmbedField{Name: name, Value: value, Inline: flags.inline})
+		}
+	}
+
+	if embed.Title == "" && embed.Description == "" {
+		return nil, fmt.Errorf("embed needs a title or a description")
+	}
+
+	return embed, nil
+}
+
+// parseColor parses "#5865F2", "0x5865F2" or a decimal colour.
+func parseColor(color string) (int, error) {
+	if color == "" {
+		return 0, nil
+	}
+
+	var value int64
+	var err error
+	switch {
+	case strings.HasPrefix(color, "#"):
+		value, err = strconv.ParseInt(color[1:], 16, 32)
+	case strings.HasPrefix(strings.ToLower(color), "0x"):
+		value, err = strconv.ParseInt(color[2:], 16, 32)
+	default:
+		value, err = strconv.ParseInt(color, 10, 32)
+	}
+	if err != nil || value < 0 || value > 0xFFFFFF {
+		return 0, fmt.Errorf("invalid embed color %q", color)
+	}
+	return int(value), nil
+}
+
+// parseTimestamp parses an RFC 3339 time or "now".
+func parseTimestamp(timestamp string) (time.Time, error) {
+	switch timestamp {
+	case "":
+		return time.Time{}, nil
+	case "now":
+		return time.Now(), nil
+	}
+
+	t, err := time.Parse(time.RFC3339, timestamp)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("invalid embed timestamp %q (expected RFC 3339 or \"now\")", timestamp)
+	}
+	return t, nil
+}
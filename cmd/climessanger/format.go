@@ -0,0 +1,76 @@
+package main
+
+import (
+	"CLIMultiChat/internal/formatter"
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/spf13/cobra"
+)
+
+var (
+	formatFrom string
+	formatTo   string
+)
+
+var formatCmd = &cobra.Command{
+	Use:   "format",
+	Short: "Перетворити Markdown на розмітку месенджера",
+	Long: `Прочитати Markdown зі stdin і вивести його в stdout у розмітці месенджера:
+slack (mrkdwn), telegram (MarkdownV2), telegram-html, discord або plain
+(простий текст без розмітки).
+
+Згадки @user:ім'я та @group:назва стають згадками кожного месенджера за
+ID з розділів users і user_groups файлу конфігурації, а @here, @channel
+та @everyone - масовими згадками (у Discord @channel стає @here). Коди
+емодзі на кшталт :white_check_mark: Slack показує сам, у Telegram вони
+стають символами Unicode, а в Discord - власними емодзі сервера з розділу
+discord.emoji файлу конфігурації або символами Unicode.
+
+Нічого не надсилається і токени не потрібні; згадки та власні емодзі
+беруться з файлу конфігурації, як і під час відправки; якщо його немає
+або його не вдалося прочитати, використовуються типові налаштування.
+Задовгий текст не розбивається на частини.`,
+	Args:        cobra.NoArgs,
+	Annotations: map[string]string{configAnnotation: configOptional},
+	RunE: func(cmd *cobra.Command, args []string) error {
+		if formatFrom != "markdown" {
+			return fmt.Errorf("unknown input format %q (expected markdown)", formatFrom)
+		}
+
+		r, err := formatRenderer(formatTo)
+		if err != nil {
+			return err
+		}
+
+		input, err := io.ReadAll(os.Stdin)
+		if err != nil {
+			return fmt.Errorf("failed to read stdin: %w", err)
+		}
+
+		cmd.SilenceUsage = true
+		if output := formatter.Render(string(input), r); output != "" {
+			fmt.Println(output)
+		}
+		return nil
+	},
+}
+
+// formatRenderer returns the renderer for an output format of format.
+func formatRenderer(name string) (formatter.Renderer, error) {
+	switch name {
+	case "slack":
+		return formatter.SlackRenderer{Directory: directory("slack")}, nil
+	case "telegram":
+		return formatter.TelegramRenderer{Directory: directory("telegram")}, nil
+	case "telegram-html":
+		return formatter.TelegramHTMLRenderer{Directory: directory("telegram")}, nil
+	case "discord":
+		return formatter.DiscordRenderer{Directory: directory("discord")}, nil
+	case "plain":
+		return formatter.PlainRenderer{}, nil
+	default:
+		return nil, fmt.Errorf("unknown output format %q (expected slack, telegram, telegram-html, discord or plain)", name)
+	}
+}
@@ -0,0 +1,114 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"strings"
+)
+
+// stdinArg is the positional argument that stands for standard input.
+const stdinArg = "-"
+
+var messageFile string
+
+// messageFromStdin records that the message was read from stdin, which makes
+// mass mentions in it safe by default.
+var messageFromStdin bool
+
+// readMessage splits the positional arguments of a send command into the
+// leading addressing arguments (channel, chat ID) and the message body.
+// minArgs is the number of addressing arguments the command needs before the
+// message. The body comes from --file if set, from stdin when the last
+// argument is "-" or when stdin is not a terminal, and from the last argument
+// otherwise. Piped input is always the body, so every argument then addresses
+// it: a message argument next to it is reported by the caller as one too many
+// rather than sent in place of the input.
+func readMessage(args []string, minArgs int) ([]string, string, error) {
+	switch {
+	case messageFile != "":
+		message, err := readMessageFile(messageFile)
+		return args, message, err
+	case len(args) > minArgs && args[len(args)-1] == stdinArg:
+		message, err := readMessageStdin()
+		return args[:len(args)-1], message, err
+	case stdinIsPiped():
+		message, err := readMessageStdin()
+		return args, message, err
+	case len(args) == minArgs:
+		return nil, "", fmt.Errorf("message is required")
+	default:
+		return args[:len(args)-1], args[len(args)-1], nil
+	}
+}
+
+func readMessageFile(path string) (string, error) {
+	if path == stdinArg {
+		return readMessageStdin()
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return "", fmt.Errorf("failed to read message file: %w", err)
+	}
+
+	return normalizeMessage(string(data))
+}
+
+func readMessageStdin() (string, error) {
+	messageFromStdin = true
+
+	data, err := io.ReadAll(os.Stdin)
+	if err != nil {
+		return "", fmt.Errorf("failed to read message from stdin: %w", err)
+	}
+
+	return normalizeMessage(string(data))
+}
+
+// normalizeMessage strips the trailing newline most tools append to their
+// output and rejects bodies that contain nothing to send.
+func normalizeMessage(message string) (string, error) {
+	message = strings.TrimRight(message, "\r\n")
+	if strings.TrimSpace(message) == "" {
+		return "", fmt.Errorf("message is empty")
+	}
+	return message, nil
+}
+
+// errTooManyArgs reports the arguments left over once the message is read.
+// With piped input, a message argument is one of them.
+func errTooManyArgs() error {
+	if messageFromStdin {
+		return fmt.Errorf("too many arguments: the message is read from stdin")
+	}
+	return fmt.Errorf("too many arguments")
+}
+
+// stdinIsPiped reports whether stdin is a pipe or a file rather than an
+// interactive terminal.
+func stdinIsPiped() bool {
+	info, err := os.Stdin.Stat()
+	if err != nil {
+		return false
+	}
+	return info.Mode()&os.ModeCharDevice == 0
+}
+
+// channelAndMessage resolves the optional channel and the message body of the
+// per-platform send commands.
+func channelAndMessage(args []string) (string, string, error) {
+	rest, message, err := readMessage(args, 0)
+	if err != nil {
+		return "", "", err
+	}
+
+	switch len(rest) {
+	case 0:
+		return "", message, nil
+	case 1:
+		return rest[0], message, nil
+	default:
+		return "", "", errTooManyArgs()
+	}
+}
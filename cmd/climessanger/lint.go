@@ -0,0 +1,133 @@
+package main
+
+import (
+	"CLIMultiChat/internal/broadcast"
+	messengers "CLIMultiChat/internal/integrations"
+	"CLIMultiChat/internal/lint"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/spf13/cobra"
+)
+
+var (
+	lintPlatform   string
+	lintStrict     bool
+	skipValidation bool
+)
+
+var lintCmd = &cobra.Command{
+	Use:   "lint [повідомлення]",
+	Short: "Перевірити повідомлення",
+	Long: `Перевірити повідомлення за правилами месенджерів, нічого не надсилаючи:
+посилання (абсолютні URL зі схемою http, https, mailto або tg), розмітку
+MarkdownV2 для Telegram, обмеження вбудовувань Discord, кількість блоків
+Slack і довжину: задовге повідомлення буде розбите на частини, а частина,
+яка й після розбиття довша за обмеження месенджера, є помилкою. Помилки
+MarkdownV2 - лише попередження: таку частину Telegram отримає в HTML.
+
+Кожна проблема виводиться окремим рядком із позицією рядок:стовпець - у
+тексті повідомлення або, для розмітки, у відрендереній частині. Код
+виходу 1 означає, що знайдено помилки (з --strict - також попередження).`,
+	Args:        cobra.RangeArgs(0, 1),
+	Annotations: map[string]string{configAnnotation: configOptional},
+	RunE: func(cmd *cobra.Command, args []string) error {
+		rest, message, err := readMessage(args, 0)
+		if err != nil {
+			return err
+		}
+		if len(rest) > 0 {
+			return errTooManyArgs()
+		}
+
+		var names []string
+		for _, p := range platforms {
+			if lintPlatform == "all" || lintPlatform == p.name {
+				names = append(names, p.name)
+			}
+		}
+		if len(names) == 0 {
+			return fmt.Errorf("unknown platform %q (expected slack, telegram, discord or all)", lintPlatform)
+		}
+
+		opts, message, err := sendOptions(message)
+		if err != nil {
+			return err
+		}
+
+		cmd.SilenceUsage = true
+		issues := lint.Check(message, names, lintSettings(), opts...)
+		printIssues(issues)
+
+		if lint.HasErrors(issues) || (lintStrict && len(issues) > 0) {
+			return &exitError{exitFailure, fmt.Errorf("знайдено проблем: %d", len(issues))}
+		}
+		if len(issues) == 0 {
+			notice("Проблем не знайдено")
+		}
+		return nil
+	},
+}
+
+func lintSettings() lint.Settings {
+	return lint.Settings{
+		SlackFormat:       cfg.SlackFormat,
+		TelegramParseMode: cfg.TelegramParseMode,
+	}
+}
+
+// validateMessage checks message for the platforms of targets before it is
+// sent and fails when it has errors, which are printed to stderr. Warnings
+// do not stop the send and are not shown.
+func validateMessage(message string, targets []broadcast.Target, opts []messengers.SendOption) error {
+	if skipValidation {
+		return nil
+	}
+
+	var names []string
+	seen := map[string]bool{}
+	for _, t := range targets {
+		name := strings.ToLower(t.Platform)
+		if t.Err == nil && !seen[name] {
+			seen[name] = true
+			names = append(names, name)
+		}
+	}
+
+	var errs []lint.Issue
+	for _, issue := range lint.Check(message, names, lintSettings(), opts...) {
+		if issue.Severity == lint.SeverityError {
+			errs = append(errs, issue)
+		}
+	}
+	if len(errs) == 0 {
+		return nil
+	}
+
+	for _, issue := range errs {
+		fmt.Fprintln(os.Stderr, issue)
+	}
+	return messengers.NewError(messengers.ErrorKindInput, fmt.Errorf("повідомлення не пройшло перевірку (помилок: %d), нічого не надіслано", len(errs)))
+}
+
+// issueRecord is the machine-readable form of a lint issue.
+type issueRecord struct {
+	Platform string        `json:"platform,omitempty"`
+	Severity lint.Severity `json:"severity"`
+	Part     int           `json:"part,omitempty"`
+	Line     int           `json:"line,omitempty"`
+	Column   int           `json:"column,omitempty"`
+	Message  string        `json:"message"`
+}
+
+// printIssues prints one issue per line, as text or JSON records.
+func printIssues(issues []lint.Issue) {
+	for _, issue := range issues {
+		if outputFormat == outputJSON {
+			printJSON(issueRecord(issue))
+			continue
+		}
+		fmt.Println(issue)
+	}
+}
@@ -1,11 +1,8 @@
 package main
 
 import (
+	"CLIMultiChat/internal/broadcast"
 	"CLIMultiChat/internal/config"
-	messengers "CLIMultiChat/internal/integrations"
-	"CLIMultiChat/internal/integrations/discord"
-	"CLIMultiChat/internal/integrations/slack"
-	"CLIMultiChat/internal/integrations/telegram"
 	"fmt"
 	"os"
 
@@ -14,96 +11,105 @@ import (
 
 var (
 	cfg         *config.Config
-	sendAllFlag bool
-	slackClient messengers.Messenger
+	loadOptions config.LoadOptions
 )
 
-func init() {
-	var err error
-	cfg, err = config.Load()
-	if err != nil {
-		fmt.Fprintf(os.Stderr, "Error loading config: %v\n", err)
-		os.Exit(1)
-	}
-}
-
 var rootCmd = &cobra.Command{
 	Use:   "climessenger",
 	Short: "Відправка повідомлень у месенджери",
 	Long:  `Програма для відправки повідомлень у Slack, Telegram та Discord.`,
+	PersistentPreRunE: func(cmd *cobra.Command, args []string) error {
+		if err := validateOutputFormat(); err != nil {
+			return err
+		}
+
+		var err error
+		cfg, err = config.Load(loadOptions)
+		if err != nil {
+			if cmd.Annotations[configAnnotation] != configOptional {
+				return fmt.Errorf("error loading config: %w", err)
+			}
+			fmt.Fprintf(os.Stderr, "Попередження: конфігурацію не завантажено (%v), використовуються типові налаштування\n", err)
+			cfg = &config.Config{}
+		}
+		return nil
+	},
 }
 
+// Commands that send nothing only take rendering settings, users and emoji
+// from the config, so they are annotated to run with the defaults when the
+// config is missing or broken.
+const (
+	configAnnotation = "config"
+	configOptional   = "optional"
+)
+
 var sendCmd = &cobra.Command{
 	Use:   "send",
 	Short: "Відправити повідомлення",
-	Long:  `Відправити повідомлення у Slack, Telegram або Discord.`,
+	Long: `Відправити повідомлення у Slack, Telegram або Discord.
+
+Текст повідомлення можна передати аргументом, прочитати з файлу (--file)
+або зі stdin ("-" замість повідомлення, або автоматично, якщо stdin не є
+терміналом: тоді всі аргументи - це канал чи chat_id, а не текст). Замість
+каналу чи chat_id можна вказати псевдонім із розділу aliases файлу
+конфігурації.
+
+Повідомлення пишеться в Markdown і перетворюється на розмітку кожного
+месенджера разом зі згадками та кодами емодзі (див. climessenger format).
+Задовге повідомлення розбивається на частини, які надсилаються у гілці
+Slack або як ланцюжок відповідей у Telegram і Discord. Front-matter (YAML
+або JSON між рядками ---) з ключами title, url, color, fields, footer і
+timestamp задає вбудовування Discord, як і прапорці --embed-*.
+
+Якщо повідомлення прочитано зі stdin, масові згадки (@here, @channel,
+@everyone та їх форми на кшталт <!channel>) нікого не сповіщають;
+--allow-mentions дозволяє їх.
+
+Перед відправкою повідомлення перевіряється так само, як у climessenger
+lint; якщо є помилки, нічого не надсилається (--no-validate вимикає
+перевірку). З --dry-run повідомлення не надсилається: для кожного
+отримувача виводиться те, що пішло б у месенджер, як у climessenger
+preview.`,
 }
 
 var slackCmd = &cobra.Command{
 	Use:   "slack [канал] [повідомлення]",
 	Short: "В Slack",
-	Long:  `Відправити повідомлення у Slack. Якщо канал не вказано, використовується стандартний.`,
-	Args:  cobra.MinimumNArgs(1),
-	RunE: func(cmd *cobra.Command, args []string) error {
-		var channel, message string
-
-		if len(args) == 1 {
-			channel = ""
-			message = args[0]
-		} else {
-			channel = args[0]
-			message = args[1]
-		}
+	Long: `Відправити повідомлення у Slack. Якщо канал не вказано, використовується стандартний.
 
-		if err := cfg.ValidateSlack(); err != nil {
-			return fmt.Errorf("slack configuration error: %w", err)
-		}
-
-		client, err := slack.NewClient(cfg.SlackToken, cfg.SlackChannel)
+З --slack-format blocks повідомлення надсилається як Block Kit: заголовки,
+абзаци, роздільники, підписи та блоки коду стають окремими блоками, а
+простий текст використовується для сповіщень.`,
+	Args: cobra.RangeArgs(0, 2),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		channel, message, err := channelAndMessage(args)
 		if err != nil {
-			return fmt.Errorf("failed to create Slack client: %w", err)
-		}
-
-		if err := client.SendMessage(channel, message); err != nil {
 			return err
 		}
 
-		fmt.Printf("Повідомлення надіслано у Slack\n")
-		return nil
+		return sendOne(cmd, slackTarget(channel), message)
 	},
 }
 
 var telegramCmd = &cobra.Command{
 	Use:   "telegram [chat_id] [повідомлення]",
 	Short: "В Telegram",
-	Long:  `Відправити повідомлення у Telegram. Якщо chat_id не вказано, використовується стандартний.`,
-	Args:  cobra.MinimumNArgs(1),
+	Long: `Відправити повідомлення у Telegram. Якщо chat_id не вказано, використовується стандартний.
+
+Форматування задає --telegram-parse-mode: entities (типово, текст зі
+списком сутностей без екранування), markdownv2, html або plain. Якщо
+Telegram не може розібрати розмітку, повідомлення надсилається повторно
+у простішому режимі (MarkdownV2 → HTML → простий текст); використаний
+режим виводиться після відправки.`,
+	Args: cobra.RangeArgs(0, 2),
 	RunE: func(cmd *cobra.Command, args []string) error {
-		var chatID, message string
-
-		if len(args) == 1 {
-			chatID = ""
-			message = args[0]
-		} else {
-			chatID = args[0]
-			message = args[1]
-		}
-
-		if err := cfg.ValidateTelegram(); err != nil {
-			return fmt.Errorf("telegram configuration error: %w", err)
-		}
-
-		client, err := telegram.NewClient(cfg.TelegramBotToken, cfg.TelegramChatID)
+		chatID, message, err := channelAndMessage(args)
 		if err != nil {
-			return fmt.Errorf("failed to create Telegram client: %w", err)
-		}
-
-		if err := client.SendMessage(chatID, message); err != nil {
 			return err
 		}
 
-		fmt.Printf("Повідомлення надіслано у Telegram\n")
-		return nil
+		return sendOne(cmd, telegramTarget(chatID), message)
 	},
 }
 
@@ -111,98 +117,119 @@ var discordCmd = &cobra.Command{
 	Use:   "discord [канал] [повідомлення]",
 	Short: "В Discord",
 	Long:  `Відправити повідомлення у Discord. Якщо канал не вказано, використовується стандартний.`,
-	Args:  cobra.MinimumNArgs(1),
+	Args:  cobra.RangeArgs(0, 2),
 	RunE: func(cmd *cobra.Command, args []string) error {
-		var channel, message string
-
-		if len(args) == 1 {
-			channel = ""
-			message = args[0]
-		} else {
-			channel = args[0]
-			message = args[1]
-		}
-
-		if err := cfg.ValidateDiscord(); err != nil {
-			return fmt.Errorf("discord configuration error: %w", err)
-		}
-
-		client, err := discord.NewClient(cfg.DiscordToken, cfg.DiscordChannel)
+		channel, message, err := channelAndMessage(args)
 		if err != nil {
-			return fmt.Errorf("failed to create Discord client: %w", err)
-		}
-
-		if err := client.SendMessage(channel, message); err != nil {
 			return err
 		}
 
-		fmt.Printf("Повідомлення надіслано у Discord\n")
-		return nil
+		return sendOne(cmd, discordTarget(channel), message)
 	},
 }
 
 var allCmd = &cobra.Command{
 	Use:   "all [повідомлення]",
 	Short: "Усі месенджери",
-	Long:  `Відправити одне повідомлення у всі месенджери (Slack, Telegram і Discord).`,
-	Args:  cobra.ExactArgs(1),
+	Long: `Відправити одне повідомлення у всі месенджери (Slack, Telegram і Discord).
+
+Повідомлення надсилається паралельно. Код виходу 2 означає, що не вдалося
+надіслати в жоден месенджер, 3 - що частину повідомлень не доставлено.`,
+	Args: cobra.RangeArgs(0, 1),
 	RunE: func(cmd *cobra.Command, args []string) error {
-		message := args[0]
-		errors := []error{}
+		rest, message, err := readMessage(args, 0)
+		if err != nil {
+			return err
+		}
+		if len(rest) > 0 {
+			return errTooManyArgs()
+		}
+
+		opts, message, err := sendOptions(message)
+		if err != nil {
+			return err
+		}
+
+		var targets []broadcast.Target
 
 		if cfg.ValidateSlack() == nil {
-			client, err := slack.NewClient(cfg.SlackToken, cfg.SlackChannel)
-			if err != nil {
-				errors = append(errors, fmt.Errorf("slack: %w", err))
-			} else {
-				if err := client.SendMessage("", message); err != nil {
-					errors = append(errors, fmt.Errorf("Slack: %w", err))
-				} else {
-					fmt.Printf("Повідомлення надіслано у Slack\n")
-				}
-			}
+			targets = append(targets, slackTarget(cfg.SlackChannel))
 		} else {
-			fmt.Println("Slack не налаштовано, пропущено")
+			notice("Slack не налаштовано, пропущено")
 		}
 
 		if cfg.ValidateTelegram() == nil {
-			client, err := telegram.NewClient(cfg.TelegramBotToken, cfg.TelegramChatID)
-			if err != nil {
-				errors = append(errors, fmt.Errorf("telegram: %w", err))
-			} else {
-				if err := client.SendMessage("", message); err != nil {
-					errors = append(errors, fmt.Errorf("Telegram: %w", err))
-				} else {
-					fmt.Printf("Повідомлення надіслано у Telegram\n")
-				}
-			}
+			targets = append(targets, telegramTarget(cfg.TelegramChatID))
 		} else {
-			fmt.Println("Telegram не налаштовано, пропущено")
+			notice("Telegram не налаштовано, пропущено")
 		}
 
 		if cfg.ValidateDiscord() == nil {
-			client, err := discord.NewClient(cfg.DiscordToken, cfg.DiscordChannel)
-			if err != nil {
-				errors = append(errors, fmt.Errorf("discord: %w", err))
-			} else {
-				if err := client.SendMessage("", message); err != nil {
-					errors = append(errors, fmt.Errorf("Discord: %w", err))
-				} else {
-					fmt.Printf("Повідомлення надіслано у Discord\n")
-				}
-			}
+			targets = append(targets, discordTarget(cfg.DiscordChannel))
 		} else {
-			fmt.Println("Discord не налаштовано, пропущено")
+			notice("Discord не налаштовано, пропущено")
 		}
 
-		if len(errors) > 0 {
-			for _, err := range errors {
-				fmt.Fprintf(os.Stderr, "Помилка: %v\n", err)
-			}
-			return fmt.Errorf("не вдалося надіслати у всі месенджери")
+		if len(targets) == 0 {
+			return fmt.Errorf("жоден месенджер не налаштовано")
 		}
 
-		return nil
+		cmd.SilenceUsage = true
+		if err := validateMessage(message, targets, opts); err != nil {
+			return err
+		}
+
+		results := broadcast.Send(targets, message, workers, opts...)
+		printResults(results)
+
+		return broadcastError(results)
+	},
+}
+
+var groupCmd = &cobra.Command{
+	Use:   "group <назва> [повідомлення]",
+	Short: "Група отримувачів",
+	Long: `Відправити повідомлення всім учасникам групи з розділу groups файлу
+конфігурації. Учасник - це "платформа:канал" або псевдонім.
+
+Повідомлення надсилається паралельно, коди виходу такі самі, як у send all.`,
+	Args: cobra.RangeArgs(1, 2),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		rest, message, err := readMessage(args, 1)
+		if err != nil {
+			return err
+		}
+		if len(rest) != 1 {
+			return errTooManyArgs()
+		}
+
+		destinations, err := cfg.Group(rest[0])
+		if err != nil {
+			return err
+		}
+		if len(destinations) == 0 {
+			return fmt.Errorf("group %q has no members", rest[0])
+		}
+
+		opts, message, err := sendOptions(message)
+		if err != nil {
+			return err
+		}
+
+		targets := make([]broadcast.Target, 0, len(destinations))
+		for _, dest := range destinations {
+			targets = append(targets, destinationTarget(dest))
+		}
+
+		cmd.SilenceUsage = true
+		if err := validateMessage(message, targets, opts); err != nil {
+			return err
+		}
+
+		results := broadcast.Send(targets, message, workers, opts...)
+		printResults(results)
+
+		return broadcastError(results)
 	},
 }
 
@@ -211,9 +238,65 @@ func main() {
 	sendCmd.AddCommand(telegramCmd)
 	sendCmd.AddCommand(discordCmd)
 	sendCmd.AddCommand(allCmd)
+	sendCmd.AddCommand(groupCmd)
 	rootCmd.AddCommand(sendCmd)
 
+	for _, p := range platforms {
+		editCmd.AddCommand(newEditCmd(p))
+		deleteCmd.AddCommand(newDeleteCmd(p))
+	}
+	rootCmd.AddCommand(editCmd)
+	rootCmd.AddCommand(deleteCmd)
+	rootCmd.AddCommand(previewCmd)
+	rootCmd.AddCommand(formatCmd)
+	rootCmd.AddCommand(lintCmd)
+
+	rootCmd.PersistentFlags().StringVar(&loadOptions.Path, "config", "", "Шлях до файлу конфігурації (типово $XDG_CONFIG_HOME/climessenger/config.yaml)")
+	rootCmd.PersistentFlags().StringVar(&loadOptions.Profile, "profile", "", "Профіль з файлу конфігурації")
+	rootCmd.PersistentFlags().StringVar(&loadOptions.Overrides.SlackToken, "slack-token", "", "Токен Slack")
+	rootCmd.PersistentFlags().StringVar(&loadOptions.Overrides.SlackChannel, "slack-channel", "", "Стандартний канал Slack")
+	rootCmd.PersistentFlags().StringVar(&loadOptions.Overrides.SlackFormat, "slack-format", "", "Формат Slack: mrkdwn або blocks (Block Kit)")
+	rootCmd.PersistentFlags().StringVar(&loadOptions.Overrides.TelegramBotToken, "telegram-token", "", "Токен бота Telegram")
+	rootCmd.PersistentFlags().StringVar(&loadOptions.Overrides.TelegramChatID, "telegram-chat-id", "", "Стандартний chat_id Telegram")
+	rootCmd.PersistentFlags().StringVar(&loadOptions.Overrides.TelegramParseMode, "telegram-parse-mode", "", "Розмітка Telegram: entities, markdownv2, html або plain")
+	rootCmd.PersistentFlags().StringVar(&loadOptions.Overrides.DiscordToken, "discord-token", "", "Токен бота Discord")
+	rootCmd.PersistentFlags().StringVar(&loadOptions.Overrides.DiscordChannel, "discord-channel", "", "Стандартний канал Discord")
+	rootCmd.PersistentFlags().StringVarP(&outputFormat, "output", "o", outputText, "Формат виводу: text або json")
+	for _, cmd := range []*cobra.Command{slackCmd, telegramCmd, discordCmd} {
+		cmd.Flags().StringVar(&replyTo, "reply-to", "", "Відповісти на повідомлення з цим ID (гілка у Slack)")
+	}
+	slackCmd.Flags().BoolVar(&threadBroadcast, "thread-broadcast", false, "Показати відповідь у гілці також у каналі")
+	for _, cmd := range []*cobra.Command{allCmd, groupCmd} {
+		cmd.Flags().IntVarP(&workers, "workers", "w", broadcast.DefaultWorkers, "Кількість паралельних відправок")
+	}
+	sendCmd.PersistentFlags().StringVarP(&messageFile, "file", "f", "", "Прочитати повідомлення з файлу (\"-\" для stdin)")
+	sendCmd.PersistentFlags().StringArrayVarP(&attachments, "attach", "a", nil, "Прикріпити файл (можна вказати кілька разів)")
+	sendCmd.PersistentFlags().BoolVar(&embedFlags.enabled, "embed", false, "Надіслати повідомлення як вбудовування Discord (embed)")
+	sendCmd.PersistentFlags().StringVar(&embedFlags.title, "embed-title", "", "Заголовок вбудовування")
+	sendCmd.PersistentFlags().StringVar(&embedFlags.url, "embed-url", "", "Посилання заголовка вбудовування")
+	sendCmd.PersistentFlags().StringVar(&embedFlags.color, "embed-color", "", "Колір вбудовування: #RRGGBB, 0xRRGGBB або число")
+	sendCmd.PersistentFlags().StringVar(&embedFlags.footer, "embed-footer", "", "Підпис вбудовування")
+	sendCmd.PersistentFlags().StringVar(&embedFlags.timestamp, "embed-timestamp", "", "Час вбудовування: RFC 3339 або now")
+	sendCmd.PersistentFlags().StringArrayVar(&embedFlags.fields, "embed-field", nil, "Поле вбудовування назва=значення (можна вказати кілька разів)")
+	sendCmd.PersistentFlags().StringArrayVar(&embedFlags.inlineFields, "embed-inline-field", nil, "Поле в рядку назва=значення (можна вказати кілька разів)")
+	sendCmd.PersistentFlags().BoolVar(&numberParts, "number-parts", false, "Нумерувати частини задовгого повідомлення: (1/3), (2/3)...")
+	sendCmd.PersistentFlags().BoolVar(&allowMentions, "allow-mentions", false, "Дозволити масові згадки (@here, @channel, @everyone) у повідомленні зі stdin")
+	sendCmd.PersistentFlags().BoolVar(&dryRun, "dry-run", false, "Показати, що буде надіслано, нічого не надсилаючи")
+	previewCmd.Flags().StringVarP(&previewPlatform, "platform", "p", "all", "Месенджер: slack, telegram, discord або all")
+	previewCmd.Flags().StringVarP(&messageFile, "file", "f", "", "Прочитати повідомлення з файлу (\"-\" для stdin)")
+	previewCmd.Flags().BoolVar(&numberParts, "number-parts", false, "Нумерувати частини задовгого повідомлення: (1/3), (2/3)...")
+	previewCmd.Flags().BoolVar(&allowMentions, "allow-mentions", false, "Дозволити масові згадки (@here, @channel, @everyone) у повідомленні зі stdin")
+	sendCmd.PersistentFlags().BoolVar(&skipValidation, "no-validate", false, "Не перевіряти повідомлення перед відправкою")
+	lintCmd.Flags().StringVarP(&lintPlatform, "platform", "p", "all", "Месенджер: slack, telegram, discord або all")
+	lintCmd.Flags().StringVarP(&messageFile, "file", "f", "", "Прочитати повідомлення з файлу (\"-\" для stdin)")
+	lintCmd.Flags().BoolVar(&lintStrict, "strict", false, "Вважати попередження помилками")
+	lintCmd.Flags().StringArrayVarP(&attachments, "attach", "a", nil, "Перевірити як підпис до файлу (можна вказати кілька разів)")
+	formatCmd.Flags().StringVar(&formatFrom, "from", "markdown", "Вхідний формат: markdown")
+	formatCmd.Flags().StringVar(&formatTo, "to", "", "Вихідний формат: slack, telegram, telegram-html, discord або plain")
+	_ = formatCmd.MarkFlagRequired("to")
+	editCmd.PersistentFlags().StringVarP(&messageFile, "file", "f", "", "Прочитати новий текст з файлу (\"-\" для stdin)")
+
 	if err := rootCmd.Execute(); err != nil {
-		os.Exit(1)
+		os.Exit(exitCode(err))
 	}
 }
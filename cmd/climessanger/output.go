@@ -0,0 +1,173 @@
+package main
+
+import (
+	"CLIMultiChat/internal/broadcast"
+	messengers "CLIMultiChat/internal/integrations"
+	"encoding/json"
+	"fmt"
+	"os"
+	"strings"
+	"text/tabwriter"
+	"time"
+)
+
+const (
+	outputText = "text"
+	outputJSON = "json"
+)
+
+var outputFormat string
+
+func validateOutputFormat() error {
+	switch outputFormat {
+	case outputText, outputJSON:
+		return nil
+	default:
+		return fmt.Errorf("unknown output format %q (expected text or json)", outputFormat)
+	}
+}
+
+// sendRecord is the machine-readable outcome of a delivery to one destination.
+type sendRecord struct {
+	Platform  string               `json:"platform"`
+	Channel   string               `json:"channel,omitempty"`
+	OK        bool                 `json:"ok"`
+	MessageID string               `json:"message_id,omitempty"`
+	Timestamp string               `json:"timestamp,omitempty"`
+	Permalink string               `json:"permalink,omitempty"`
+	Parts     []string             `json:"parts,omitempty"`
+	ParseMode string               `json:"parse_mode,omitempty"`
+	PartModes []string             `json:"part_parse_modes,omitempty"`
+	Preview   *previewRecord       `json:"preview,omitempty"`
+	LatencyMS int64                `json:"latency_ms"`
+	Error     string               `json:"error,omitempty"`
+	ErrorKind messengers.ErrorKind `json:"error_kind,omitempty"`
+}
+
+func newSendRecord(r broadcast.Result) sendRecord {
+	record := sendRecord{
+		Platform:  r.Platform,
+		Channel:   r.Channel,
+		OK:        r.Err == nil,
+		LatencyMS: r.Latency.Milliseconds(),
+	}
+	if r.Receipt != nil {
+		if r.Receipt.Channel != "" {
+			record.Channel = r.Receipt.Channel
+		}
+		record.MessageID = r.Receipt.MessageID
+		record.Permalink = r.Receipt.Permalink
+		record.Parts = r.Receipt.Parts
+		record.ParseMode = r.Receipt.ParseMode
+		record.PartModes = r.Receipt.PartParseModes
+		if r.Receipt.Preview != nil {
+			record.Preview = newPreviewRecord(r.Receipt.Preview)
+		}
+		if !r.Receipt.Timestamp.IsZero() {
+			record.Timestamp = r.Receipt.Timestamp.UTC().Format(time.RFC3339Nano)
+		}
+	}
+	if r.Err != nil {
+		record.Error = r.Err.Error()
+		record.ErrorKind = messengers.KindOf(r.Err)
+	}
+	return record
+}
+
+// printJSON writes v as a single line of JSON to stdout.
+func printJSON(v any) {
+	if err := json.NewEncoder(os.Stdout).Encode(v); err != nil {
+		fmt.Fprintf(os.Stderr, "Помилка: %v\n", err)
+	}
+}
+
+// notice prints an informational line. In JSON mode it goes to stderr so
+// stdout only carries records.
+func notice(format string, args ...any) {
+	if outputFormat == outputJSON {
+		fmt.Fprintf(os.Stderr, format+"\n", args...)
+		return
+	}
+	fmt.Printf(format+"\n", args...)
+}
+
+// printOutcome reports an operation on a single destination. done is the
+// text-mode success line, formatted with the platform name.
+func printOutcome(r broadcast.Result, done string) {
+	if outputFormat == outputJSON {
+		printJSON(newSendRecord(r))
+		return
+	}
+
+	if r.Err != nil {
+		return
+	}
+
+	if r.Receipt != nil && r.Receipt.Preview != nil {
+		printPreview(r.Receipt.Preview)
+		return
+	}
+
+	fmt.Printf(done+"\n", r.Platform)
+	if r.Receipt != nil {
+		fmt.Printf("ID: %s\n", r.Receipt.MessageID)
+		if len(r.Receipt.Parts) > 0 {
+			fmt.Printf("Продовження: %s\n", strings.Join(r.Receipt.Parts, ", "))
+		}
+		if len(r.Receipt.PartParseModes) > 0 {
+			fmt.Printf("Розмітка частин: %s\n", strings.Join(r.Receipt.PartParseModes, ", "))
+		} else if r.Receipt.ParseMode != "" {
+			fmt.Printf("Розмітка: %s\n", r.Receipt.ParseMode)
+		}
+		if r.Receipt.Permalink != "" {
+			fmt.Printf("Посилання: %s\n", r.Receipt.Permalink)
+		}
+	}
+}
+
+// printResults reports a broadcast: a per-destination table in text mode and
+// one record per line in JSON mode.
+func printResults(results []broadcast.Result) {
+	if outputFormat == outputJSON {
+		for _, r := range results {
+			printJSON(newSendRecord(r))
+		}
+		return
+	}
+
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "МЕСЕНДЖЕР\tКАНАЛ\tСТАТУС\tID\tЧАС\tПОМИЛКА")
+
+	for _, r := range results {
+		channel := r.Channel
+		if channel == "" {
+			channel = "-"
+		}
+
+		messageID := "-"
+		if r.Receipt != nil {
+			messageID = r.Receipt.MessageID
+			if r.Receipt.Channel != "" {
+				channel = r.Receipt.Channel
+			}
+		}
+
+		status, errText := "OK", ""
+		if r.Err != nil {
+			status, errText = "ПОМИЛКА", r.Err.Error()
+		}
+
+		fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%s\t%s\n", r.Platform, channel, status, messageID, r.Latency.Round(time.Millisecond), errText)
+	}
+
+	w.Flush()
+
+	// In a dry run the table only says what would go where; the messages
+	// themselves follow it.
+	for _, r := range results {
+		if r.Receipt != nil && r.Receipt.Preview != nil {
+			fmt.Println()
+			printPreview(r.Receipt.Preview)
+		}
+	}
+}
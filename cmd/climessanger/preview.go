@@ -0,0 +1,166 @@
+package main
+
+import (
+	messengers "CLIMultiChat/internal/integrations"
+	"CLIMultiChat/internal/integrations/discord"
+	"CLIMultiChat/internal/integrations/slack"
+	"CLIMultiChat/internal/integrations/telegram"
+	"encoding/json"
+	"fmt"
+	"os"
+
+	"github.com/spf13/cobra"
+)
+
+var (
+	dryRun          bool
+	previewPlatform string
+)
+
+var previewCmd = &cobra.Command{
+	Use:   "preview [повідомлення]",
+	Short: "Показати, що буде надіслано",
+	Long: `Показати повідомлення так, як його надіслав би кожен месенджер: текст
+кожної частини, розмітку та інші поля запиту (блоки Slack, сутності
+Telegram, вбудовування Discord). Нічого не надсилається, токени не
+потрібні.
+
+Формат Slack, режим розмітки Telegram, користувачі та емодзі беруться з
+конфігурації, як і під час відправки.`,
+	Args:        cobra.RangeArgs(0, 1),
+	Annotations: map[string]string{configAnnotation: configOptional},
+	RunE: func(cmd *cobra.Command, args []string) error {
+		rest, message, err := readMessage(args, 0)
+		if err != nil {
+			return err
+		}
+		if len(rest) > 0 {
+			return errTooManyArgs()
+		}
+
+		var names []string
+		for _, p := range platforms {
+			if previewPlatform == "all" || previewPlatform == p.name {
+				names = append(names, p.name)
+			}
+		}
+		if len(names) == 0 {
+			return fmt.Errorf("unknown platform %q (expected slack, telegram, discord or all)", previewPlatform)
+		}
+
+		opts, message, err := sendOptions(message)
+		if err != nil {
+			return err
+		}
+
+		cmd.SilenceUsage = true
+		for _, name := range names {
+			preview, err := previewMessage(name, message, opts...)
+			if err != nil {
+				return err
+			}
+			printPreview(preview)
+		}
+		return nil
+	},
+}
+
+// previewMessage renders message for platform with the configured settings.
+func previewMessage(platform, message string, opts ...messengers.SendOption) (*messengers.Preview, error) {
+	switch platform {
+	case "slack":
+		return slack.Preview(message, cfg.SlackFormat, directory(platform), opts...)
+	case "telegram":
+		return telegram.Preview(message, cfg.TelegramParseMode, directory(platform), opts...)
+	case "discord":
+		return discord.Preview(message, directory(platform), opts...)
+	default:
+		return nil, fmt.Errorf("unknown platform %q", platform)
+	}
+}
+
+// previewClient stands in for a client under --dry-run: it renders messages
+// the way the real client would and returns the preview in the receipt
+// instead of sending anything.
+type previewClient struct {
+	platform       string
+	name           string
+	defaultChannel string
+}
+
+func (c previewClient) SendMessage(channel, message string, opts ...messengers.SendOption) (*messengers.Receipt, error) {
+	if channel == "" {
+		channel = c.defaultChannel
+	}
+
+	preview, err := previewMessage(c.platform, message, opts...)
+	if err != nil {
+		return nil, err
+	}
+
+	return &messengers.Receipt{
+		Platform:  c.name,
+		Channel:   channel,
+		ParseMode: preview.ParseMode,
+		Preview:   preview,
+	}, nil
+}
+
+func (c previewClient) EditMessage(channel, messageID, message string) (*messengers.Receipt, error) {
+	return nil, messengers.NewError(messengers.ErrorKindInput, fmt.Errorf("editing is not supported in a dry run"))
+}
+
+func (c previewClient) DeleteMessage(channel, messageID string) error {
+	return messengers.NewError(messengers.ErrorKindInput, fmt.Errorf("deleting is not supported in a dry run"))
+}
+
+func (c previewClient) GetName() string {
+	return c.name
+}
+
+// previewRecord is the machine-readable form of a preview.
+type previewRecord struct {
+	Platform  string              `json:"platform"`
+	ParseMode string              `json:"parse_mode,omitempty"`
+	Parts     []previewPartRecord `json:"parts"`
+}
+
+type previewPartRecord struct {
+	Text   string         `json:"text,omitempty"`
+	Fields map[string]any `json:"fields,omitempty"`
+}
+
+func newPreviewRecord(p *messengers.Preview) *previewRecord {
+	record := &previewRecord{Platform: p.Platform, ParseMode: p.ParseMode}
+	for _, part := range p.Parts {
+		record.Parts = append(record.Parts, previewPartRecord{Text: part.Text, Fields: part.Fields})
+	}
+	return record
+}
+
+// printPreview shows a preview: a header per part, its text and its other
+// request fields as indented JSON.
+func printPreview(p *messengers.Preview) {
+	if outputFormat == outputJSON {
+		printJSON(newPreviewRecord(p))
+		return
+	}
+
+	fmt.Printf("=== %s (розмітка: %s, частин: %d) ===\n", p.Platform, p.ParseMode, len(p.Parts))
+	for i, part := range p.Parts {
+		if len(p.Parts) > 1 {
+			fmt.Printf("--- частина %d/%d ---\n", i+1, len(p.Parts))
+		}
+		if part.Text != "" {
+			fmt.Println(part.Text)
+		}
+		if len(part.Fields) > 0 {
+			data, err := json.MarshalIndent(part.Fields, "", "  ")
+			if err != nil {
+				fmt.Fprintf(os.Stderr, "Помилка: %v\n", err)
+				continue
+			}
+			fmt.Println(string(data))
+		}
+	}
+}
@@ -0,0 +1,152 @@
+package main
+
+import (
+	"CLIMultiChat/internal/broadcast"
+	"CLIMultiChat/internal/config"
+	"CLIMultiChat/internal/formatter"
+	messengers "CLIMultiChat/internal/integrations"
+	"CLIMultiChat/internal/integrations/discord"
+	"CLIMultiChat/internal/integrations/slack"
+	"CLIMultiChat/internal/integrations/telegram"
+	"fmt"
+)
+
+// clients holds one client per platform for the lifetime of the command, so
+// destinations on the same platform share a connection.
+var clients = map[string]cachedResult{}
+
+type cachedResult struct {
+	client messengers.Messenger
+	err    error
+}
+
+func cachedClient(platform string, create func() (messengers.Messenger, error)) (messengers.Messenger, error) {
+	if cached, ok := clients[platform]; ok {
+		return cached.client, cached.err
+	}
+
+	client, err := create()
+	clients[platform] = cachedResult{client, err}
+	return client, err
+}
+
+// destinationTarget builds the target of a configured destination.
+func destinationTarget(dest config.Destination) broadcast.Target {
+	switch dest.Platform {
+	case "slack":
+		return slackTarget(dest.Channel)
+	case "telegram":
+		return telegramTarget(dest.Channel)
+	case "discord":
+		return discordTarget(dest.Channel)
+	default:
+		return inputTarget(dest.Platform, dest.Channel, fmt.Errorf("unknown platform %q", dest.Platform))
+	}
+}
+
+func slackTarget(channel string) broadcast.Target {
+	if err := validateConfig(cfg.ValidateSlack); err != nil {
+		return configTarget("Slack", channel, fmt.Errorf("slack configuration error: %w", err))
+	}
+
+	resolved, err := cfg.ResolveChannel("slack", channel)
+	if err != nil {
+		return inputTarget("Slack", channel, err)
+	}
+
+	client, err := cachedClient("slack", func() (messengers.Messenger, error) {
+		if dryRun {
+			return previewClient{"slack", "Slack", cfg.SlackChannel}, nil
+		}
+		return slack.NewClient(cfg.SlackToken, cfg.SlackChannel, cfg.SlackFormat, directory("slack"))
+	})
+	return newTarget("Slack", resolved, client, err)
+}
+
+func telegramTarget(chatID string) broadcast.Target {
+	if err := validateConfig(cfg.ValidateTelegram); err != nil {
+		return configTarget("Telegram", chatID, fmt.Errorf("telegram configuration error: %w", err))
+	}
+
+	resolved, err := cfg.ResolveChannel("telegram", chatID)
+	if err != nil {
+		return inputTarget("Telegram", chatID, err)
+	}
+
+	client, err := cachedClient("telegram", func() (messengers.Messenger, error) {
+		if dryRun {
+			return previewClient{"telegram", "Telegram", cfg.TelegramChatID}, nil
+		}
+		return telegram.NewClient(cfg.TelegramBotToken, cfg.TelegramChatID, cfg.TelegramParseMode, directory("telegram"))
+	})
+	return newTarget("Telegram", resolved, client, err)
+}
+
+func discordTarget(channel string) broadcast.Target {
+	if err := validateConfig(cfg.ValidateDiscord); err != nil {
+		return configTarget("Discord", channel, fmt.Errorf("discord configuration error: %w", err))
+	}
+
+	resolved, err := cfg.ResolveChannel("discord", channel)
+	if err != nil {
+		return inputTarget("Discord", channel, err)
+	}
+
+	client, err := cachedClient("discord", func() (messengers.Messenger, error) {
+		if dryRun {
+			return previewClient{"discord", "Discord", cfg.DiscordChannel}, nil
+		}
+		return discord.NewClient(cfg.DiscordToken, cfg.DiscordChannel, directory("discord"))
+	})
+	return newTarget("Discord", resolved, client, err)
+}
+
+// validateConfig runs the config check of a platform. A dry run sends
+// nothing and needs no token, so the check is left to the preview, which
+// still rejects an unknown format or parse mode.
+func validateConfig(validate func() error) error {
+	if dryRun {
+		return nil
+	}
+	return validate()
+}
+
+// directory is the mention and custom emoji directory of platform from the
+// config.
+func directory(platform string) formatter.Directory {
+	users, groups := cfg.MentionIDs(platform)
+	d := formatter.Directory{Users: users, Groups: groups}
+	if platform == "discord" {
+		d.Emoji = cfg.DiscordEmoji
+	}
+	return d
+}
+
+func configTarget(platform, channel string, err error) broadcast.Target {
+	return broadcast.Target{
+		Platform: platform,
+		Channel:  channel,
+		Err:      messengers.NewError(messengers.ErrorKindConfig, err),
+	}
+}
+
+func inputTarget(platform, channel string, err error) broadcast.Target {
+	return broadcast.Target{
+		Platform: platform,
+		Channel:  channel,
+		Err:      messengers.NewError(messengers.ErrorKindInput, err),
+	}
+}
+
+func newTarget(platform, channel string, client messengers.Messenger, err error) broadcast.Target {
+	if err != nil {
+		err = messengers.NewError(messengers.KindOf(err), fmt.Errorf("failed to create %s client: %w", platform, err))
+	}
+
+	return broadcast.Target{
+		Platform:  platform,
+		Channel:   channel,
+		Messenger: client,
+		Err:       err,
+	}
+}
@@ -0,0 +1,91 @@
+package broadcast
+
+import (
+	messengers "CLIMultiChat/internal/integrations"
+	"sync"
+	"time"
+)
+
+// DefaultWorkers is the number of destinations served concurrently when the
+// caller does not ask for a specific limit.
+const DefaultWorkers = 4
+
+// Target is a single destination of a broadcast. Err is set when the
+// destination could not be prepared (for example the client failed to
+// connect); Send then reports it without contacting the messenger.
+type Target struct {
+	Platform  string
+	Channel   string
+	Messenger messengers.Messenger
+	Err       error
+}
+
+// Result is the outcome of sending to a single Target.
+type Result struct {
+	Platform string
+	Channel  string
+	Receipt  *messengers.Receipt
+	Latency  time.Duration
+	Err      error
+}
+
+// Send delivers message to every target using at most workers concurrent
+// senders. Results are returned in the same order as targets.
+func Send(targets []Target, message string, workers int, opts ...messengers.SendOption) []Result {
+	if workers <= 0 {
+		workers = DefaultWorkers
+	}
+	if workers > len(targets) {
+		workers = len(targets)
+	}
+
+	results := make([]Result, len(targets))
+	jobs := make(chan int)
+
+	var wg sync.WaitGroup
+	for w := 0; w < workers; w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range jobs {
+				results[i] = send(targets[i], message, opts)
+			}
+		}()
+	}
+
+	for i := range targets {
+		jobs <- i
+	}
+	close(jobs)
+	wg.Wait()
+
+	return results
+}
+
+func send(target Target, message string, opts []messengers.SendOption) Result {
+	result := Result{
+		Platform: target.Platform,
+		Channel:  target.Channel,
+		Err:      target.Err,
+	}
+	if target.Err != nil {
+		return result
+	}
+
+	start := time.Now()
+	result.Receipt, result.Err = target.Messenger.SendMessage(target.Channel, message, opts...)
+	result.Latency = time.Since(start)
+
+	return result
+}
+
+// Failed returns the number of results that carry an error.
+func Failed(results []Result) int {
+	failed := 0
+	for _, r := range results {
+		if r.Err != nil {
+			failed++
+		}
+	}
+	return failed
+}
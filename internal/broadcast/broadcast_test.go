@@ -0,0 +1,73 @@
+package broadcast
+
+import (
+	messengers "CLIMultiChat/internal/integrations"
+	"errors"
+	"sync/atomic"
+	"testing"
+)
+
+// fakeMessenger records the messages it gets and fails when err is set.
+type fakeMessenger struct {
+	err  error
+	sent atomic.Int32
+}
+
+func (m *fakeMessenger) SendMessage(channel, message string, opts ...messengers.SendOption) (*messengers.Receipt, error) {
+	m.sent.Add(1)
+	if m.err != nil {
+		return nil, m.err
+	}
+	return &messengers.Receipt{Channel: channel, MessageID: "id-" + channel}, nil
+}
+
+func (m *fakeMessenger) EditMessage(channel, messageID, message string) (*messengers.Receipt, error) {
+	return nil, nil
+}
+
+func (m *fakeMessenger) DeleteMessage(channel, messageID string) error {
+	return nil
+}
+
+func (m *fakeMessenger) GetName() string {
+	return "Fake"
+}
+
+func TestSend(t *testing.T) {
+	ok := &fakeMessenger{}
+	failing := &fakeMessenger{err: errors.New("rejected")}
+	unprepared := &fakeMessenger{}
+
+	targets := []Target{
+		{Platform: "A", Channel: "1", Messenger: ok},
+		{Platform: "B", Channel: "2", Messenger: failing},
+		{Platform: "C", Channel: "3", Messenger: unprepared, Err: errors.New("no token")},
+		{Platform: "D", Channel: "4", Messenger: ok},
+	}
+
+	for _, workers := range []int{0, 1, 2, 10} {
+		results := Send(targets, "hello", workers)
+
+		if len(results) != len(targets) {
+			t.Fatalf("workers %d: got %d results, want %d", workers, len(results), len(targets))
+		}
+		for i, r := range results {
+			if r.Platform != targets[i].Platform || r.Channel != targets[i].Channel {
+				t.Errorf("workers %d: result %d is for %s %s, want results in target order", workers, i, r.Platform, r.Channel)
+			}
+		}
+		if results[0].Err != nil || results[0].Receipt == nil || results[0].Receipt.MessageID != "id-1" {
+			t.Errorf("workers %d: result 0 = %+v, want a receipt", workers, results[0])
+		}
+		if results[1].Err == nil || results[2].Err == nil {
+			t.Errorf("workers %d: failed targets reported no error", workers)
+		}
+		if got := Failed(results); got != 2 {
+			t.Errorf("workers %d: Failed = %d, want 2", workers, got)
+		}
+	}
+
+	if n := unprepared.sent.Load(); n != 0 {
+		t.Errorf("a target with an error was sent %d messages, want none", n)
+	}
+}
@@ -3,6 +3,7 @@ package config
 import (
 	"fmt"
 	"os"
+	"strings"
 
 	"github.com/joho/godotenv"
 )
@@ -14,27 +15,227 @@ type Config struct {
 	TelegramChatID   string
 	DiscordToken     string
 	DiscordChannel   string
+
+	// SlackFormat is how Slack messages are posted: "mrkdwn" (the default)
+	// or "blocks" for Block Kit.
+	SlackFormat string
+	// TelegramParseMode is how Telegram messages are formatted first:
+	// "entities" (the default), "markdownv2", "html" or "plain".
+	TelegramParseMode string
+
+	// Aliases maps short names to destinations, so they can be used in place
+	// of raw channel IDs and chat IDs.
+	Aliases map[string]Destination
+	// Groups maps broadcast group names to their members. A member is either
+	// "platform:channel" or the name of an alias.
+	Groups map[string][]string
+	// Users maps names used in @user:name mentions to their IDs on each
+	// platform, and UserGroups does the same for @group:name.
+	Users      map[string]Identity
+	UserGroups map[string]Identity
+	// DiscordEmoji maps the shortcodes of Discord custom emoji to their IDs.
+	DiscordEmoji map[string]string
+}
+
+// Destination is a channel (or chat) on a particular platform.
+type Destination struct {
+	Platform string `yaml:"platform"`
+	Channel  string `yaml:"channel"`
 }
 
-func Load() (*Config, error) {
+// Identity is a person or group as known to each platform: a Slack member or
+// user group ID, a Telegram user ID, and a Discord user or role ID. Any of
+// them may be empty.
+type Identity struct {
+	Slack    string `yaml:"slack"`
+	Telegram string `yaml:"telegram"`
+	Discord  string `yaml:"discord"`
+}
+
+// id returns the ID of i on platform.
+func (i Identity) id(platform string) string {
+	switch platform {
+	case "slack":
+		return i.Slack
+	case "telegram":
+		return i.Telegram
+	case "discord":
+		return i.Discord
+	default:
+		return ""
+	}
+}
+
+// LoadOptions controls where Load looks for settings.
+type LoadOptions struct {
+	// Path is an explicit config file. When empty, CLIMESSENGER_CONFIG and
+	// then the XDG location are tried, and a missing file is not an error.
+	Path string
+	// Profile selects a named profile from the config file. When empty,
+	// CLIMESSENGER_PROFILE and then the file's default_profile are used.
+	Profile string
+	// Overrides holds values set by command-line flags. Non-empty fields
+	// take precedence over the environment and the config file.
+	Overrides Config
+}
+
+// Load builds the configuration with the precedence
+// flags > environment > config file > defaults.
+func Load(opts LoadOptions) (*Config, error) {
 	_ = godotenv.Load()
 
-	config := &Config{
-		SlackToken:       os.Getenv("SLACK_TOKEN"),
-		SlackChannel:     os.Getenv("SLACK_CHANNEL"),
-		TelegramBotToken: os.Getenv("TELEGRAM_BOT_TOKEN"),
-		TelegramChatID:   os.Getenv("TELEGRAM_CHAT_ID"),
-		DiscordToken:     os.Getenv("DISCORD_TOKEN"),
-		DiscordChannel:   os.Getenv("DISCORD_CHANNEL"),
+	config := &Config{}
+
+	file, err := loadFile(opts.Path)
+	if err != nil {
+		return nil, err
+	}
+	if file != nil {
+		profile := opts.Profile
+		if profile == "" {
+			profile = os.Getenv("CLIMESSENGER_PROFILE")
+		}
+		if err := file.apply(config, profile); err != nil {
+			return nil, err
+		}
+	} else if opts.Profile != "" {
+		return nil, fmt.Errorf("profile %q requested but no config file found", opts.Profile)
+	}
+
+	config.merge(Config{
+		SlackToken:        os.Getenv("SLACK_TOKEN"),
+		SlackChannel:      os.Getenv("SLACK_CHANNEL"),
+		TelegramBotToken:  os.Getenv("TELEGRAM_BOT_TOKEN"),
+		TelegramChatID:    os.Getenv("TELEGRAM_CHAT_ID"),
+		DiscordToken:      os.Getenv("DISCORD_TOKEN"),
+		DiscordChannel:    os.Getenv("DISCORD_CHANNEL"),
+		SlackFormat:       os.Getenv("SLACK_FORMAT"),
+		TelegramParseMode: os.Getenv("TELEGRAM_PARSE_MODE"),
+	})
+	config.merge(opts.Overrides)
+
+	if err := config.resolveDefaults(); err != nil {
+		return nil, err
 	}
 
 	return config, nil
 }
 
+// merge copies the non-empty fields of other into c.
+func (c *Config) merge(other Config) {
+	set := func(dst *string, src string) {
+		if src != "" {
+			*dst = src
+		}
+	}
+
+	set(&c.SlackToken, other.SlackToken)
+	set(&c.SlackChannel, other.SlackChannel)
+	set(&c.TelegramBotToken, other.TelegramBotToken)
+	set(&c.TelegramChatID, other.TelegramChatID)
+	set(&c.DiscordToken, other.DiscordToken)
+	set(&c.DiscordChannel, other.DiscordChannel)
+	set(&c.SlackFormat, other.SlackFormat)
+	set(&c.TelegramParseMode, other.TelegramParseMode)
+}
+
+// ResolveChannel returns the channel an alias stands for on platform
+// ("slack", "telegram" or "discord"). Names that are not aliases are returned
+// unchanged; an alias of another platform is an error.
+func (c *Config) ResolveChannel(platform, channel string) (string, error) {
+	alias, ok := c.Aliases[channel]
+	if !ok {
+		return channel, nil
+	}
+	if alias.Platform != platform {
+		return "", fmt.Errorf("alias %q is a %s destination, not %s", channel, alias.Platform, platform)
+	}
+	return alias.Channel, nil
+}
+
+// Group returns the destinations of the named broadcast group.
+func (c *Config) Group(name string) ([]Destination, error) {
+	members, ok := c.Groups[name]
+	if !ok {
+		return nil, fmt.Errorf("group %q not found in config file", name)
+	}
+
+	destinations := make([]Destination, 0, len(members))
+	for _, member := range members {
+		dest, err := c.parseMember(member)
+		if err != nil {
+			return nil, fmt.Errorf("group %q: %w", name, err)
+		}
+		destinations = append(destinations, dest)
+	}
+
+	return destinations, nil
+}
+
+func (c *Config) parseMember(member string) (Destination, error) {
+	if platform, channel, ok := strings.Cut(member, ":"); ok && isPlatform(platform) {
+		if channel == "" {
+			return Destination{}, fmt.Errorf("member %q has no channel", member)
+		}
+		return Destination{Platform: platform, Channel: channel}, nil
+	}
+
+	if alias, ok := c.Aliases[member]; ok {
+		return alias, nil
+	}
+
+	return Destination{}, fmt.Errorf("member %q is neither platform:channel nor a known alias", member)
+}
+
+// MentionIDs returns the IDs of the configured users and user groups on
+// platform, keyed by the names used in mentions. Names with no ID on the
+// platform are left out.
+func (c *Config) MentionIDs(platform string) (users, groups map[string]string) {
+	ids := func(identities map[string]Identity) map[string]string {
+		out := make(map[string]string)
+		for name, identity := range identities {
+			if id := identity.id(platform); id != "" {
+				out[name] = id
+			}
+		}
+		return out
+	}
+	return ids(c.Users), ids(c.UserGroups)
+}
+
+func isPlatform(name string) bool {
+	switch name {
+	case "slack", "telegram", "discord":
+		return true
+	default:
+		return false
+	}
+}
+
+// resolveDefaults lets the default channels be given as aliases too.
+func (c *Config) resolveDefaults() error {
+	var err error
+	if c.SlackChannel, err = c.ResolveChannel("slack", c.SlackChannel); err != nil {
+		return err
+	}
+	if c.TelegramChatID, err = c.ResolveChannel("telegram", c.TelegramChatID); err != nil {
+		return err
+	}
+	if c.DiscordChannel, err = c.ResolveChannel("discord", c.DiscordChannel); err != nil {
+		return err
+	}
+	return nil
+}
+
 func (c *Config) ValidateSlack() error {
 	if c.SlackToken == "" {
 		return fmt.Errorf("SLACK_TOKEN is missing")
 	}
+	switch c.SlackFormat {
+	case "", "mrkdwn", "blocks":
+	default:
+		return fmt.Errorf("unknown Slack format %q (expected mrkdwn or blocks)", c.SlackFormat)
+	}
 	return nil
 }
 
@@ -42,6 +243,11 @@ func (c *Config) ValidateTelegram() error {
 	if c.TelegramBotToken == "" {
 		return fmt.Errorf("TELEGRAM_BOT_TOKEN is missing")
 	}
+	switch strings.ToLower(c.TelegramParseMode) {
+	case "", "entities", "markdownv2", "html", "plain":
+	default:
+		return fmt.Errorf("unknown Telegram parse mode %q (expected entities, markdownv2, html or plain)", c.TelegramParseMode)
+	}
 	return nil
 }
 
@@ -0,0 +1,328 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// configEnv lists the environment variables Load reads.
+var configEnv = []string{
+	"SLACK_TOKEN", "SLACK_CHANNEL", "SLACK_FORMAT",
+	"TELEGRAM_BOT_TOKEN", "TELEGRAM_CHAT_ID", "TELEGRAM_PARSE_MODE",
+	"DISCORD_TOKEN", "DISCORD_CHANNEL",
+	"CLIMESSENGER_CONFIG", "CLIMESSENGER_PROFILE",
+}
+
+// isolate clears the environment Load reads and points the default config
+// location at an empty directory.
+func isolate(t *testing.T) {
+	t.Helper()
+	for _, name := range configEnv {
+		t.Setenv(name, "")
+	}
+	dir := t.TempDir()
+	t.Setenv("XDG_CONFIG_HOME", dir)
+	t.Setenv("HOME", dir)
+	t.Chdir(dir)
+}
+
+// writeConfig writes a config file and returns its path. Tabs in yaml are
+// turned into the two spaces of a YAML indentation level.
+func writeConfig(t *testing.T, yaml string) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "config.yaml")
+	if err := os.WriteFile(path, []byte(strings.ReplaceAll(yaml, "\t", "  ")), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+const testConfig = `
+default_profile: dev
+slack:
+	token: file-token
+	channel: C-FILE
+	format: blocks
+telegram:
+	bot_token: file-bot
+	chat_id: "100"
+profiles:
+	dev:
+		slack:
+			channel: C-DEV
+	prod:
+		slack:
+			token: prod-token
+			channel: C-PROD
+		telegram:
+			parse_mode: html
+`
+
+func TestLoadPrecedence(t *testing.T) {
+	isolate(t)
+	path := writeConfig(t, testConfig)
+
+	t.Setenv("SLACK_CHANNEL", "C-ENV")
+	t.Setenv("TELEGRAM_CHAT_ID", "200")
+
+	cfg, err := Load(LoadOptions{Path: path, Overrides: Config{TelegramChatID: "300"}})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		field, got, want string
+	}{
+		{"SlackToken", cfg.SlackToken, "file-token"},  // file
+		{"SlackFormat", cfg.SlackFormat, "blocks"},    // file
+		{"SlackChannel", cfg.SlackChannel, "C-ENV"},   // environment over the default profile
+		{"TelegramChatID", cfg.TelegramChatID, "300"}, // flag over the environment
+		{"TelegramBotToken", cfg.TelegramBotToken, "file-bot"},
+		{"DiscordToken", cfg.DiscordToken, ""},
+	}
+	for _, tt := range tests {
+		if tt.got != tt.want {
+			t.Errorf("%s = %q, want %q", tt.field, tt.got, tt.want)
+		}
+	}
+}
+
+func TestLoadProfiles(t *testing.T) {
+	tests := []struct {
+		name      string
+		profile   string
+		env       string // CLIMESSENGER_PROFILE
+		channel   string
+		token     string
+		parseMode string
+		err       string
+	}{
+		{name: "default profile", channel: "C-DEV", token: "file-token"},
+		{name: "flag", profile: "prod", channel: "C-PROD", token: "prod-token", parseMode: "html"},
+		{name: "environment", env: "prod", channel: "C-PROD", token: "prod-token", parseMode: "html"},
+		{name: "flag over environment", profile: "dev", env: "prod", channel: "C-DEV", token: "file-token"},
+		{name: "unknown", profile: "staging", err: `profile "staging" not found`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			isolate(t)
+			path := writeConfig(t, testConfig)
+			t.Setenv("CLIMESSENGER_PROFILE", tt.env)
+
+			cfg, err := Load(LoadOptions{Path: path, Profile: tt.profile})
+			if tt.err != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.err) {
+					t.Fatalf("Load error = %v, want %q", err, tt.err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if cfg.SlackChannel != tt.channel || cfg.SlackToken != tt.token || cfg.TelegramParseMode != tt.parseMode {
+				t.Errorf("got channel %q, token %q, parse mode %q; want %q, %q, %q",
+					cfg.SlackChannel, cfg.SlackToken, cfg.TelegramParseMode, tt.channel, tt.token, tt.parseMode)
+			}
+		})
+	}
+}
+
+func TestLoadConfigFile(t *testing.T) {
+	t.Run("missing default file", func(t *testing.T) {
+		isolate(t)
+		if _, err := Load(LoadOptions{}); err != nil {
+			t.Errorf("Load without a config file = %v, want no error", err)
+		}
+	})
+
+	t.Run("missing explicit file", func(t *testing.T) {
+		isolate(t)
+		if _, err := Load(LoadOptions{Path: filepath.Join(t.TempDir(), "none.yaml")}); err == nil {
+			t.Error("Load with a missing --config file succeeded, want an error")
+		}
+	})
+
+	t.Run("profile without a file", func(t *testing.T) {
+		isolate(t)
+		if _, err := Load(LoadOptions{Profile: "prod"}); err == nil {
+			t.Error("Load with a profile and no config file succeeded, want an error")
+		}
+	})
+
+	t.Run("environment path", func(t *testing.T) {
+		isolate(t)
+		t.Setenv("CLIMESSENGER_CONFIG", writeConfig(t, testConfig))
+		cfg, err := Load(LoadOptions{})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if cfg.SlackToken != "file-token" {
+			t.Errorf("SlackToken = %q, want the one from CLIMESSENGER_CONFIG", cfg.SlackToken)
+		}
+	})
+
+	t.Run("invalid YAML", func(t *testing.T) {
+		isolate(t)
+		if _, err := Load(LoadOptions{Path: writeConfig(t, "slack: [")}); err == nil {
+			t.Error("Load of invalid YAML succeeded, want an error")
+		}
+	})
+}
+
+const aliasConfig = `
+slack:
+	token: t
+	channel: ops
+aliases:
+	ops:
+		platform: slack
+		channel: C-OPS
+	family:
+		platform: telegram
+		channel: "-100"
+profiles:
+	prod:
+		aliases:
+			ops:
+				platform: slack
+				channel: C-PROD-OPS
+`
+
+func TestResolveChannel(t *testing.T) {
+	isolate(t)
+	cfg, err := Load(LoadOptions{Path: writeConfig(t, aliasConfig)})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// The default channel may be an alias too.
+	if cfg.SlackChannel != "C-OPS" {
+		t.Errorf("SlackChannel = %q, want the alias resolved to %q", cfg.SlackChannel, "C-OPS")
+	}
+
+	tests := []struct {
+		platform, channel string
+		want              string
+		err               bool
+	}{
+		{platform: "slack", channel: "ops", want: "C-OPS"},
+		{platform: "telegram", channel: "family", want: "-100"},
+		{platform: "slack", channel: "C-RAW", want: "C-RAW"},
+		{platform: "discord", channel: "ops", err: true},
+	}
+	for _, tt := range tests {
+		got, err := cfg.ResolveChannel(tt.platform, tt.channel)
+		if (err != nil) != tt.err || got != tt.want {
+			t.Errorf("ResolveChannel(%q, %q) = %q, %v; want %q, error %v", tt.platform, tt.channel, got, err, tt.want, tt.err)
+		}
+	}
+}
+
+func TestProfileAliases(t *testing.T) {
+	isolate(t)
+	cfg, err := Load(LoadOptions{Path: writeConfig(t, aliasConfig), Profile: "prod"})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if got, _ := cfg.ResolveChannel("slack", "ops"); got != "C-PROD-OPS" {
+		t.Errorf("ops = %q, want the profile's %q", got, "C-PROD-OPS")
+	}
+	if got, _ := cfg.ResolveChannel("telegram", "family"); got != "-100" {
+		t.Errorf("family = %q, want the top-level alias kept", got)
+	}
+}
+
+func TestInvalidAliases(t *testing.T) {
+	tests := []struct {
+		name string
+		yaml string
+		err  string
+	}{
+		{"unknown platform", "aliases:\n\tx:\n\t\tplatform: matrix\n\t\tchannel: c", `unknown platform "matrix"`},
+		{"no channel", "aliases:\n\tx:\n\t\tplatform: slack", "channel is required"},
+		{"default of another platform", "slack:\n\tchannel: fam\naliases:\n\tfam:\n\t\tplatform: telegram\n\t\tchannel: \"1\"", "not slack"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			isolate(t)
+			_, err := Load(LoadOptions{Path: writeConfig(t, tt.yaml)})
+			if err == nil || !strings.Contains(err.Error(), tt.err) {
+				t.Errorf("Load error = %v, want %q", err, tt.err)
+			}
+		})
+	}
+}
+
+func TestGroup(t *testing.T) {
+	isolate(t)
+	yaml := aliasConfig + `groups:
+	release:
+		- slack:C0123
+		- discord:#general
+		- telegram:-1001:extra
+		- ops
+	broken:
+		- ops
+		- nowhere
+	empty-channel:
+		- "slack:"
+`
+	cfg, err := Load(LoadOptions{Path: writeConfig(t, yaml)})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	got, err := cfg.Group("release")
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []Destination{
+		{Platform: "slack", Channel: "C0123"},
+		{Platform: "discord", Channel: "#general"},
+		{Platform: "telegram", Channel: "-1001:extra"},
+		{Platform: "slack", Channel: "C-OPS"},
+	}
+	if len(got) != len(want) {
+		t.Fatalf("Group = %v, want %v", got, want)
+	}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Errorf("member %d = %v, want %v", i, got[i], want[i])
+		}
+	}
+
+	for _, tt := range []struct{ name, err string }{
+		{"broken", `member "nowhere" is neither`},
+		{"empty-channel", "has no channel"},
+		{"missing", `group "missing" not found`},
+	} {
+		if _, err := cfg.Group(tt.name); err == nil || !strings.Contains(err.Error(), tt.err) {
+			t.Errorf("Group(%q) error = %v, want %q", tt.name, err, tt.err)
+		}
+	}
+}
+
+func TestProfileGroupsUseProfileAliases(t *testing.T) {
+	// Members are resolved when the group is used, so a top-level group
+	// follows the aliases of the selected profile.
+	isolate(t)
+	yaml := aliasConfig + "groups:\n\tteam:\n\t\t- ops\n"
+	cfg, err := Load(LoadOptions{Path: writeConfig(t, yaml), Profile: "prod"})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	got, err := cfg.Group("team")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(got) != 1 || got[0].Channel != "C-PROD-OPS" {
+		t.Errorf("Group = %v, want the prod ops channel", got)
+	}
+}
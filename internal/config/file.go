@@ -0,0 +1,225 @@
+package config
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+
+	"gopkg.in/yaml.v3"
+)
+
+// fileConfig is the layout of the YAML config file:
+//
+//	default_profile: dev
+//	slack:
+//	  token: xoxb-...
+//	  channel: C0123ABC
+//	  format: blocks
+//	telegram:
+//	  bot_token: 123:ABC
+//	  chat_id: "-1001234567890"
+//	  parse_mode: html
+//	discord:
+//	  token: ...
+//	  channel: "1234567890"
+//	  emoji:
+//	    partyparrot: "393561224361394176"
+//	aliases:
+//	  ops:
+//	    platform: slack
+//	    channel: "#ops-alerts"
+//	groups:
+//	  release-announce:
+//	    - slack:C0123ABC
+//	    - slack:#general
+//	    - discord:1234567890
+//	    - ops
+//	users:
+//	  alice:
+//	    slack: U0123ABC
+//	    telegram: "123456789"
+//	    discord: "80351110224678912"
+//	user_groups:
+//	  sre:
+//	    slack: S0123ABC
+//	    discord: "41771983423143936"
+//	profiles:
+//	  prod:
+//	    slack:
+//	      channel: C0PROD
+//
+// Top-level settings apply to every profile; the selected profile overrides
+// them field by field and adds its own aliases, groups, users, user groups
+// and emoji.
+type fileConfig struct {
+	DefaultProfile string             `yaml:"default_profile"`
+	Profiles       map[string]profile `yaml:"profiles"`
+	profile        `yaml:",inline"`
+}
+
+type profile struct {
+	Slack struct {
+		Token   string `yaml:"token"`
+		Channel string `yaml:"channel"`
+		Format  string `yaml:"format"`
+	} `yaml:"slack"`
+	Telegram struct {
+		BotToken  string `yaml:"bot_token"`
+		ChatID    string `yaml:"chat_id"`
+		ParseMode string `yaml:"parse_mode"`
+	} `yaml:"telegram"`
+	Discord struct {
+		Token   string            `yaml:"token"`
+		Channel string            `yaml:"channel"`
+		Emoji   map[string]string `yaml:"emoji"`
+	} `yaml:"discord"`
+	Aliases    map[string]Destination `yaml:"aliases"`
+	Groups     map[string][]string    `yaml:"groups"`
+	Users      map[string]Identity    `yaml:"users"`
+	UserGroups map[string]Identity    `yaml:"user_groups"`
+}
+
+// DefaultPath returns the XDG location of the config file,
+// $XDG_CONFIG_HOME/climessenger/config.yaml.
+func DefaultPath() (string, error) {
+	dir, err := os.UserConfigDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dir, "climessenger", "config.yaml"), nil
+}
+
+// loadFile reads the config file. It returns nil without an error when no
+// path was requested explicitly and the default file does not exist.
+func loadFile(path string) (*fileConfig, error) {
+	explicit := true
+	if path == "" {
+		path = os.Getenv("CLIMESSENGER_CONFIG")
+	}
+	if path == "" {
+		explicit = false
+
+		var err error
+		path, err = DefaultPath()
+		if err != nil {
+			return nil, nil
+		}
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		if !explicit && errors.Is(err, fs.ErrNotExist) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to read config file: %w", err)
+	}
+
+	var file fileConfig
+	if err := yaml.Unmarshal(data, &file); err != nil {
+		return nil, fmt.Errorf("failed to parse config file %s: %w", path, err)
+	}
+
+	return &file, nil
+}
+
+// apply merges the top-level settings and then the named profile into c.
+func (f *fileConfig) apply(c *Config, name string) error {
+	c.merge(f.profile.config())
+	if err := c.addAliases(f.Aliases); err != nil {
+		return err
+	}
+	c.addGroups(f.Groups)
+	c.addIdentities(f.Users, f.UserGroups)
+	c.addEmoji(f.Discord.Emoji)
+
+	if name == "" {
+		name = f.DefaultProfile
+	}
+	if name == "" {
+		return nil
+	}
+
+	p, ok := f.Profiles[name]
+	if !ok {
+		return fmt.Errorf("profile %q not found in config file", name)
+	}
+	c.merge(p.config())
+	if err := c.addAliases(p.Aliases); err != nil {
+		return err
+	}
+	c.addGroups(p.Groups)
+	c.addIdentities(p.Users, p.UserGroups)
+	c.addEmoji(p.Discord.Emoji)
+
+	return nil
+}
+
+func (p profile) config() Config {
+	return Config{
+		SlackToken:       p.Slack.Token,
+		SlackChannel:     p.Slack.Channel,
+		TelegramBotToken: p.Telegram.BotToken,
+		TelegramChatID:   p.Telegram.ChatID,
+		DiscordToken:     p.Discord.Token,
+		DiscordChannel:   p.Discord.Channel,
+
+		SlackFormat:       p.Slack.Format,
+		TelegramParseMode: p.Telegram.ParseMode,
+	}
+}
+
+// addAliases adds aliases to c, replacing ones with the same name.
+func (c *Config) addAliases(aliases map[string]Destination) error {
+	for name, dest := range aliases {
+		if !isPlatform(dest.Platform) {
+			return fmt.Errorf("alias %q: unknown platform %q", name, dest.Platform)
+		}
+		if dest.Channel == "" {
+			return fmt.Errorf("alias %q: channel is required", name)
+		}
+
+		if c.Aliases == nil {
+			c.Aliases = make(map[string]Destination)
+		}
+		c.Aliases[name] = dest
+	}
+	return nil
+}
+
+// addGroups adds groups to c, replacing ones with the same name. Members are
+// resolved when a group is used, so they may refer to aliases of any level.
+func (c *Config) addGroups(groups map[string][]string) {
+	for name, members := range groups {
+		if c.Groups == nil {
+			c.Groups = make(map[string][]string)
+		}
+		c.Groups[name] = members
+	}
+}
+
+// addIdentities adds users and user groups to c, replacing ones with the
+// same name.
+func (c *Config) addIdentities(users, groups map[string]Identity) {
+	add := func(dst *map[string]Identity, src map[string]Identity) {
+		for name, identity := range src {
+			if *dst == nil {
+				*dst = make(map[string]Identity)
+			}
+			(*dst)[name] = identity
+		}
+	}
+	add(&c.Users, users)
+	add(&c.UserGroups, groups)
+}
+
+// addEmoji adds Discord custom emoji to c, replacing ones with the same name.
+func (c *Config) addEmoji(emoji map[string]string) {
+	for name, id := range emoji {
+		if c.DiscordEmoji == nil {
+			c.DiscordEmoji = make(map[string]string)
+		}
+		c.DiscordEmoji[name] = id
+	}
+}
@@ -0,0 +1,123 @@
+package formatter
+
+import "strings"
+
+// NodeKind identifies the type of a Node in a parsed Markdown document.
+type NodeKind int
+
+const (
+	// Block nodes
+	KindDocument NodeKind = iota
+	KindParagraph
+	KindHeading
+	KindSubtext
+	KindCodeBlock
+	KindBlockQuote
+	KindList
+	KindListItem
+	KindThematicBreak
+	KindTable
+	KindTableRow
+	KindTableCell
+
+	// Inline nodes
+	KindText
+	KindEmphasis
+	KindStrong
+	KindStrikethrough
+	KindCode
+	KindSpoiler
+	KindLink
+	KindMention
+	KindEmoji
+	KindSoftBreak
+)
+
+// Node is an element of the intermediate document tree produced by Parse and
+// consumed by the platform renderers.
+type Node struct {
+	Kind     NodeKind
+	Children []*Node
+
+	// Literal is the text of Text and Code nodes, the content of code
+	// blocks, the name in a mention and the shortcode of an emoji.
+	Literal string
+	// Info is the language of a fenced code block and the type of a mention
+	// (MentionUser, MentionHere, ...).
+	Info string
+	// Level is the level (1-6) of a heading.
+	Level int
+	// Ordered and Start describe a numbered list and its first number.
+	Ordered bool
+	Start   int
+	// URL is the target of a link.
+	URL string
+	// Align holds the column alignments of a table. The first row of a
+	// table is its header.
+	Align []Alignment
+}
+
+// Alignment is the alignment of a table column.
+type Alignment int
+
+const (
+	AlignNone Alignment = iota
+	AlignLeft
+	AlignCenter
+	AlignRight
+)
+
+// IsBlock reports whether n is a block-level node.
+func (n *Node) IsBlock() bool {
+	return n.Kind < KindText
+}
+
+// WithoutStrong returns a copy of n in which bold spans are replaced by their
+// content, for renderers that show a whole block in bold and cannot nest
+// bold inside it.
+func (n *Node) WithoutStrong() *Node {
+	clone := *n
+	clone.Children = nil
+	for _, child := range n.Children {
+		if child.Kind == KindStrong {
+			clone.Children = append(clone.Children, child.WithoutStrong().Children...)
+			continue
+		}
+		clone.Children = append(clone.Children, child.WithoutStrong())
+	}
+	return &clone
+}
+
+// PlainText returns the text content of n with all formatting removed.
+func (n *Node) PlainText() string {
+	switch n.Kind {
+	case KindText, KindCode, KindCodeBlock:
+		return n.Literal
+	case KindSoftBreak:
+		return "\n"
+	case KindMention:
+		return "@" + n.Literal
+	case KindEmoji:
+		return emojiText(n.Literal)
+	}
+
+	var b strings.Builder
+	for i, child := range n.Children {
+		if i > 0 {
+			switch child.Kind {
+			case KindTableRow:
+				b.WriteString("\n")
+			case KindTableCell:
+				b.WriteString(" | ")
+			case KindListItem:
+				b.WriteString("\n")
+			default:
+				if child.IsBlock() {
+					b.WriteString("\n\n")
+				}
+			}
+		}
+		b.WriteString(child.PlainText())
+	}
+	return b.String()
+}
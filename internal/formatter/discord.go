@@ -0,0 +1,207 @@
+package formatter
+
+import (
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// DiscordRenderer renders documents in Discord's Markdown dialect, which
+// differs from CommonMark:
+// - **bold** for bold (__x__ means underline, not bold)
+// - *italic* for italic
+// - ~~strike~~ for strikethrough
+// - ||spoiler|| for spoilers
+// - `code` and ```lang code block``` for code
+// - [text](url) for masked links
+// - #, ## and ### for headings, -# for subtext
+// - - and 1. for lists, > for quotes
+// - <@123> and <@&123> for user and role mentions with IDs from Directory,
+// @here and @everyone for mass mentions; Discord has no @channel, so it
+// becomes the narrower @here rather than @everyone, which would also reach
+// members who are offline
+// - <:name:123> for custom emoji with IDs from Directory; other shortcodes
+// become Unicode emoji
+//
+// With SafeMentions, mass mentions, including ones in the text itself, get a
+// zero-width space after the @ so they notify no one.
+//
+// Text is escaped so that characters Discord would treat as formatting come
+// out literally. Bullets are normalised to -, rules are drawn with
+// box-drawing characters and tables are laid out as aligned monospace text
+// in a code block.
+type DiscordRenderer struct {
+	Directory    Directory
+	SafeMentions bool
+}
+
+var discordListStyle = listStyle{
+	bullet: func(int) string { return "-" },
+	number: func(n int) string { return strconv.Itoa(n) + "." },
+	indent: "  ",
+}
+
+func (r DiscordRenderer) Render(doc *Node) string {
+	return joinBlocks(doc.Children, r.block)
+}
+
+func (r DiscordRenderer) block(n *Node) string {
+	switch n.Kind {
+	case KindCodeBlock:
+		fence := codeBlockFence(n.Literal)
+		return fence + n.Info + "\n" + n.Literal + "\n" + fence
+	case KindTable:
+		text := renderTableText(n)
+		fence := codeBlockFence(text)
+		return fence + "\n" + text + "\n" + fence
+	case KindHeading:
+		// Discord only has three heading sizes; deeper levels become bold.
+		if n.Level > 3 {
+			return "**" + r.inlines(n.WithoutStrong()) + "**"
+		}
+		return strings.Repeat("#", n.Level) + " " + r.inlines(n)
+	case KindList:
+		return renderList(n, 0, discordListStyle, r.block)
+	case KindBlockQuote:
+		return renderQuote(n, "> ", ">", r.block)
+	case KindThematicBreak:
+		return thematicBreak
+	case KindSubtext:
+		return "-# " + r.inlines(n)
+	default:
+		return r.inlines(n)
+	}
+}
+
+// inlines renders the inline children of a block, escaping text that starts
+// a line so it cannot turn into a heading, quote or list.
+func (r DiscordRenderer) inlines(n *Node) string {
+	var b strings.Builder
+	lineStart := true
+	for _, child := range n.Children {
+		out := r.inline(child)
+		if lineStart && child.Kind == KindText {
+			out = escapeDiscordLineStart(out)
+		}
+		b.WriteString(out)
+		lineStart = child.Kind == KindSoftBreak
+	}
+	return b.String()
+}
+
+func (r DiscordRenderer) inline(n *Node) string {
+	switch n.Kind {
+	case KindText:
+		if r.SafeMentions {
+			return discordMassMentionRe.ReplaceAllString(escapeDiscord(n.Literal), "@"+zeroWidthSpace+"$1")
+		}
+		return escapeDiscord(n.Literal)
+	case KindSoftBreak:
+		return "\n"
+	case KindCode:
+		fence := codeFence(n.Literal)
+		if strings.HasPrefix(n.Literal, "`") || strings.HasSuffix(n.Literal, "`") {
+			return fence + " " + n.Literal + " " + fence
+		}
+		return fence + n.Literal + fence
+	case KindStrong:
+		return "**" + renderChildren(n, r.inline) + "**"
+	case KindEmphasis:
+		return "*" + renderChildren(n, r.inline) + "*"
+	case KindStrikethrough:
+		return "~~" + renderChildren(n, r.inline) + "~~"
+	case KindSpoiler:
+		return "||" + renderChildren(n, r.inline) + "||"
+	case KindMention:
+		switch n.Info {
+		case MentionHere, MentionEveryone, MentionChannel:
+			name := n.Info
+			if name == MentionChannel {
+				name = MentionHere
+			}
+			if r.SafeMentions {
+				return "@" + zeroWidthSpace + name
+			}
+			return "@" + name
+		}
+		if id, ok := r.Directory.lookup(n); ok {
+			if n.Info == MentionGroup {
+				return "<@&" + id + ">"
+			}
+			return "<@" + id + ">"
+		}
+		return escapeDiscord(n.PlainText())
+	case KindEmoji:
+		if id, ok := r.Directory.Emoji[n.Literal]; ok {
+			return "<:" + n.Literal + ":" + id + ">"
+		}
+		return escapeDiscord(n.PlainText())
+	case KindLink:
+		if n.PlainText() == n.URL {
+			return n.URL
+		}
+		return "[" + renderChildren(n, r.inline) + "](" + n.URL + ")"
+	default:
+		return renderChildren(n, r.inline)
+	}
+}
+
+var (
+	// discordURLRe matches bare URLs, which Discord links itself and which
+	// must not be escaped.
+	discordURLRe = regexp.MustCompile(`https?://[^\s<>]+`)
+	// discordLineStartRe matches text that would start a heading, quote or
+	// list when it begins a line.
+	discordLineStartRe = regexp.MustCompile(`^(#|>|-|\+|\d+[.)])`)
+	// discordMassMentionRe matches @everyone and @here written as text.
+	discordMassMentionRe = regexp.MustCompile(`@(everyone|here)`)
+)
+
+// zeroWidthSpace breaks up a mention without changing how it looks.
+const zeroWidthSpace = "\u200b"
+
+// escapeDiscord escapes the characters that would otherwise trigger
+// formatting in Discord: \ * _ ~ ` | [ ].
+func escapeDiscord(text string) string {
+	var b strings.Builder
+	last := 0
+	for _, loc := range discordURLRe.FindAllStringIndex(text, -1) {
+		b.WriteString(escapeChars(text[last:loc[0]], "\\*_~`|[]"))
+		b.WriteString(text[loc[0]:loc[1]])
+		last = loc[1]
+	}
+	b.WriteString(escapeChars(text[last:], "\\*_~`|[]"))
+	return b.String()
+}
+
+func escapeDiscordLineStart(text string) string {
+	if discordLineStartRe.MatchString(text) {
+		if text[0] >= '0' && text[0] <= '9' {
+			// "1. item" is escaped on the dot: "1\. item".
+			i := strings.IndexAny(text, ".)")
+			return text[:i] + "\\" + text[i:]
+		}
+		return "\\" + text
+	}
+	return text
+}
+
+// codeFence returns a backtick run longer than any run inside code.
+func codeFence(code string) string {
+	longest, run := 0, 0
+	for i := 0; i < len(code); i++ {
+		if code[i] == '`' {
+			run++
+			longest = max(longest, run)
+		} else {
+			run = 0
+		}
+	}
+	return strings.Repeat("`", longest+1)
+}
+
+// codeBlockFence returns the fence of a code block: three backticks, or
+// more when code holds a run of three or more.
+func codeBlockFence(code string) string {
+	return strings.Repeat("`", max(3, len(codeFence(code))))
+}
@@ -0,0 +1,60 @@
+package formatter
+
+import "testing"
+
+func TestDiscordCodeBlockFence(t *testing.T) {
+	tests := []struct {
+		name string
+		code string
+		want string
+	}{
+		{"plain", "x := 1", "```\nx := 1\n```"},
+		{"short runs", "a `b` ``c``", "```\na `b` ``c``\n```"},
+		{"fence inside", "```go\nx\n```", "````\n```go\nx\n```\n````"},
+		{"longer run", "`````", "``````\n`````\n``````"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			doc := &Node{Kind: KindDocument, Children: []*Node{{Kind: KindCodeBlock, Literal: tt.code}}}
+			got := DiscordRenderer{}.Render(doc)
+			if got != tt.want {
+				t.Fatalf("Render = %q, want %q", got, tt.want)
+			}
+
+			// The block parses back to the same code.
+			back := Parse(got)
+			if len(back.Children) != 1 || back.Children[0].Literal != tt.code {
+				t.Errorf("%q does not parse back to %q", got, tt.code)
+			}
+		})
+	}
+}
+
+func TestDiscordEscaping(t *testing.T) {
+	tests := []struct {
+		text string
+		want string
+	}{
+		{`2 \* 3 = 6`, `2 \* 3 = 6`},
+		{"snake_case_name", `snake\_case\_name`},
+		{"see https://example.com/a_b*c", "see https://example.com/a_b*c"},
+		{"a|b and `c|d`", "a\\|b and `c|d`"},
+		{`\[x\](y)`, `\[x\](y)`},
+		{`\~~x~~ and a\\b`, `\~\~x\~\~ and a\\b`},
+		{`\# not a heading`, `\# not a heading`},
+		{`\> not a quote`, `\> not a quote`},
+		{`\- not an item`, `\- not an item`},
+		{`1\. not a list`, `1\. not a list`},
+		{`-\# not subtext`, `\-# not subtext`},
+		{"**bold_text**", `**bold\_text**`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.text, func(t *testing.T) {
+			if got := Render(tt.text, DiscordRenderer{}); got != tt.want {
+				t.Errorf("Render(%q) = %q, want %q", tt.text, got, tt.want)
+			}
+		})
+	}
+}
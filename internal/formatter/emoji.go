@@ -0,0 +1,308 @@
+package formatter
+
+import "regexp"
+
+// emojiRe matches an emoji shortcode such as :white_check_mark: or :+1:.
+var emojiRe = regexp.MustCompile(`^:([a-z0-9_+-]+):`)
+
+// parseEmoji parses a shortcode at text[i:]. A colon that follows a letter
+// or digit, as in 10:30:00, does not start one.
+func parseEmoji(text string, i int) (*Node, int, bool) {
+	if i > 0 && isAlnum(text[i-1]) {
+		return nil, 0, false
+	}
+
+	m := emojiRe.FindStringSubmatch(text[i:])
+	if m == nil {
+		return nil, 0, false
+	}
+	return &Node{Kind: KindEmoji, Literal: m[1]}, i + len(m[0]), true
+}
+
+func isAlnum(c byte) bool {
+	return c >= 'a' && c <= 'z' || c >= 'A' && c <= 'Z' || c >= '0' && c <= '9'
+}
+
+// emojiText is the Unicode form of a shortcode, or the shortcode itself
+// when it is not a standard one.
+func emojiText(name string) string {
+	if emoji, ok := emojiShortcodes[name]; ok {
+		return emoji
+	}
+	return ":" + name + ":"
+}
+
+// emojiShortcodes maps the standard shortcodes, as Slack and GitHub name
+// them, to Unicode.
+var emojiShortcodes = map[string]string{
+	// Status and reactions
+	"white_check_mark":            "✅",
+	"heavy_check_mark":            "✔️",
+	"ballot_box_with_check":       "☑️",
+	"x":                           "❌",
+	"negative_squared_cross_mark": "❎",
+	"heavy_multiplication_x":      "✖️",
+	"warning":                     "⚠️",
+	"no_entry":                    "⛔",
+	"no_entry_sign":               "🚫",
+	"exclamation":                 "❗",
+	"heavy_exclamation_mark":      "❗",
+	"grey_exclamation":            "❕",
+	"question":                    "❓",
+	"grey_question":               "❔",
+	"bangbang":                    "‼️",
+	"information_source":          "ℹ️",
+	"bell":                        "🔔",
+	"no_bell":                     "🔕",
+	"rotating_light":              "🚨",
+	"sos":                         "🆘",
+	"new":                         "🆕",
+	"ok":                          "🆗",
+	"cool":                        "🆒",
+	"up":                          "🆙",
+	"+1":                          "👍",
+	"thumbsup":                    "👍",
+	"-1":                          "👎",
+	"thumbsdown":                  "👎",
+	"ok_hand":                     "👌",
+	"clap":                        "👏",
+	"raised_hands":                "🙌",
+	"pray":                        "🙏",
+	"wave":                        "👋",
+	"point_right":                 "👉",
+	"point_left":                  "👈",
+	"point_up":                    "☝️",
+	"point_down":                  "👇",
+	"muscle":                      "💪",
+	"eyes":                        "👀",
+	"handshake":                   "🤝",
+	"v":                           "✌️",
+	"crossed_fingers":             "🤞",
+
+	// Faces
+	"smile":                  "😄",
+	"smiley":                 "😃",
+	"grinning":               "😀",
+	"grin":                   "😁",
+	"laughing":               "😆",
+	"joy":                    "😂",
+	"rofl":                   "🤣",
+	"slightly_smiling_face":  "🙂",
+	"upside_down_face":       "🙃",
+	"wink":                   "😉",
+	"blush":                  "😊",
+	"innocent":               "😇",
+	"heart_eyes":             "😍",
+	"star_struck":            "🤩",
+	"kissing_heart":          "😘",
+	"yum":                    "😋",
+	"stuck_out_tongue":       "😛",
+	"thinking_face":          "🤔",
+	"thinking":               "🤔",
+	"neutral_face":           "😐",
+	"expressionless":         "😑",
+	"no_mouth":               "😶",
+	"smirk":                  "😏",
+	"unamused":               "😒",
+	"roll_eyes":              "🙄",
+	"grimacing":              "😬",
+	"relieved":               "😌",
+	"pensive":                "😔",
+	"sleepy":                 "😪",
+	"sleeping":               "😴",
+	"mask":                   "😷",
+	"face_with_thermometer":  "🤒",
+	"nauseated_face":         "🤢",
+	"exploding_head":         "🤯",
+	"sunglasses":             "😎",
+	"nerd_face":              "🤓",
+	"confused":               "😕",
+	"worried":                "😟",
+	"slightly_frowning_face": "🙁",
+	"open_mouth":             "😮",
+	"astonished":             "😲",
+	"flushed":                "😳",
+	"fearful":                "😨",
+	"cold_sweat":             "😰",
+	"cry":                    "😢",
+	"sob":                    "😭",
+	"scream":                 "😱",
+	"confounded":             "😖",
+	"disappointed":           "😞",
+	"sweat":                  "😓",
+	"weary":                  "😩",
+	"tired_face":             "😫",
+	"triumph":                "😤",
+	"rage":                   "😡",
+	"angry":                  "😠",
+	"skull":                  "💀",
+	"poop":                   "💩",
+	"hankey":                 "💩",
+	"clown_face":             "🤡",
+	"ghost":                  "👻",
+	"alien":                  "👽",
+	"robot_face":             "🤖",
+	"robot":                  "🤖",
+	"see_no_evil":            "🙈",
+	"hear_no_evil":           "🙉",
+	"speak_no_evil":          "🙊",
+
+	// Hearts and symbols
+	"heart":                   "❤️",
+	"orange_heart":            "🧡",
+	"yellow_heart":            "💛",
+	"green_heart":             "💚",
+	"blue_heart":              "💙",
+	"purple_heart":            "💜",
+	"black_heart":             "🖤",
+	"broken_heart":            "💔",
+	"sparkling_heart":         "💖",
+	"100":                     "💯",
+	"boom":                    "💥",
+	"collision":               "💥",
+	"sparkles":                "✨",
+	"star":                    "⭐",
+	"star2":                   "🌟",
+	"dizzy":                   "💫",
+	"zap":                     "⚡",
+	"fire":                    "🔥",
+	"tada":                    "🎉",
+	"confetti_ball":           "🎊",
+	"balloon":                 "🎈",
+	"gift":                    "🎁",
+	"trophy":                  "🏆",
+	"medal":                   "🏅",
+	"first_place_medal":       "🥇",
+	"dart":                    "🎯",
+	"checkered_flag":          "🏁",
+	"triangular_flag_on_post": "🚩",
+	"red_circle":              "🔴",
+	"large_orange_circle":     "🟠",
+	"large_yellow_circle":     "🟡",
+	"large_green_circle":      "🟢",
+	"large_blue_circle":       "🔵",
+	"large_purple_circle":     "🟣",
+	"black_circle":            "⚫",
+	"white_circle":            "⚪",
+	"red_square":              "🟥",
+	"green_square":            "🟩",
+	"yellow_square":           "🟨",
+	"arrow_up":                "⬆️",
+	"arrow_down":              "⬇️",
+	"arrow_left":              "⬅️",
+	"arrow_right":             "➡️",
+	"arrows_counterclockwise": "🔄",
+	"repeat":                  "🔁",
+	"heavy_plus_sign":         "➕",
+	"heavy_minus_sign":        "➖",
+	"hourglass":               "⌛",
+	"hourglass_flowing_sand":  "⏳",
+	"stopwatch":               "⏱️",
+	"alarm_clock":             "⏰",
+	"clock1":                  "🕐",
+	"calendar":                "📆",
+	"date":                    "📅",
+
+	// Objects and work
+	"rocket":                     "🚀",
+	"bug":                        "🐛",
+	"wrench":                     "🔧",
+	"hammer":                     "🔨",
+	"hammer_and_wrench":          "🛠️",
+	"gear":                       "⚙️",
+	"construction":               "🚧",
+	"lock":                       "🔒",
+	"unlock":                     "🔓",
+	"key":                        "🔑",
+	"shield":                     "🛡️",
+	"mag":                        "🔍",
+	"bulb":                       "💡",
+	"memo":                       "📝",
+	"pencil":                     "📝",
+	"pencil2":                    "✏️",
+	"pushpin":                    "📌",
+	"paperclip":                  "📎",
+	"link":                       "🔗",
+	"clipboard":                  "📋",
+	"package":                    "📦",
+	"books":                      "📚",
+	"book":                       "📖",
+	"bookmark":                   "🔖",
+	"label":                      "🏷️",
+	"file_folder":                "📁",
+	"open_file_folder":           "📂",
+	"page_facing_up":             "📄",
+	"chart_with_upwards_trend":   "📈",
+	"chart_with_downwards_trend": "📉",
+	"bar_chart":                  "📊",
+	"email":                      "📧",
+	"envelope":                   "✉️",
+	"inbox_tray":                 "📥",
+	"outbox_tray":                "📤",
+	"mailbox":                    "📫",
+	"speech_balloon":             "💬",
+	"loudspeaker":                "📢",
+	"mega":                       "📣",
+	"computer":                   "💻",
+	"desktop_computer":           "🖥️",
+	"keyboard":                   "⌨️",
+	"floppy_disk":                "💾",
+	"cd":                         "💿",
+	"iphone":                     "📱",
+	"telephone_receiver":         "📞",
+	"battery":                    "🔋",
+	"electric_plug":              "🔌",
+	"satellite":                  "📡",
+	"moneybag":                   "💰",
+	"dollar":                     "💵",
+	"credit_card":                "💳",
+	"coffee":                     "☕",
+	"beer":                       "🍺",
+	"beers":                      "🍻",
+	"pizza":                      "🍕",
+	"cake":                       "🍰",
+	"birthday":                   "🎂",
+	"santa":                      "🎅",
+	"christmas_tree":             "🎄",
+
+	// Nature and weather
+	"sunny":                "☀️",
+	"cloud":                "☁️",
+	"umbrella":             "☔",
+	"snowflake":            "❄️",
+	"rainbow":              "🌈",
+	"ocean":                "🌊",
+	"earth_africa":         "🌍",
+	"earth_americas":       "🌎",
+	"earth_asia":           "🌏",
+	"globe_with_meridians": "🌐",
+	"seedling":             "🌱",
+	"evergreen_tree":       "🌲",
+	"four_leaf_clover":     "🍀",
+	"cactus":               "🌵",
+	"sunflower":            "🌻",
+	"rose":                 "🌹",
+	"dog":                  "🐶",
+	"cat":                  "🐱",
+	"penguin":              "🐧",
+	"snake":                "🐍",
+	"turtle":               "🐢",
+	"snail":                "🐌",
+	"unicorn":              "🦄",
+	"whale":                "🐳",
+	"octopus":              "🐙",
+	"crab":                 "🦀",
+	"duck":                 "🦆",
+	"owl":                  "🦉",
+	"bee":                  "🐝",
+	"ant":                  "🐜",
+	"zzz":                  "💤",
+	"car":                  "🚗",
+	"train":                "🚆",
+	"airplane":             "✈️",
+	"ship":                 "🚢",
+	"house":                "🏠",
+	"office":               "🏢",
+	"hospital":             "🏥",
+	"flag-ua":              "🇺🇦",
+}
@@ -0,0 +1,59 @@
+package formatter
+
+import "testing"
+
+func TestRenderEmoji(t *testing.T) {
+	text := ":tada: :white_check_mark: :party_blob: :unknown_code:"
+
+	tests := []struct {
+		name     string
+		renderer Renderer
+		want     string
+	}{
+		// Slack knows the shortcodes itself, custom ones included.
+		{"slack", SlackRenderer{Directory: testDirectory}, ":tada: :white_check_mark: :party_blob: :unknown_code:"},
+		{"discord", DiscordRenderer{Directory: testDirectory}, `🎉 ✅ <:party_blob:123> :unknown\_code:`},
+		{"telegram", TelegramRenderer{Directory: testDirectory}, `🎉 ✅ :party\_blob: :unknown\_code:`},
+		{"telegram-html", TelegramHTMLRenderer{}, "🎉 ✅ :party_blob: :unknown_code:"},
+		{"entities", EntityRenderer{}, "🎉 ✅ :party_blob: :unknown_code:"},
+		{"plain", PlainRenderer{}, "🎉 ✅ :party_blob: :unknown_code:"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := Render(text, tt.renderer); got != tt.want {
+				t.Errorf("Render(%q) = %q, want %q", text, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestParseEmojiBoundaries(t *testing.T) {
+	// Colons in times, ratios and words are not shortcodes.
+	tests := []string{
+		"at 10:30:45",
+		"key:value:",
+		"a::b",
+		": spaced :",
+		"`:tada:`",
+		"https://example.com/:tada:",
+	}
+
+	for _, text := range tests {
+		var walk func(n *Node) bool
+		walk = func(n *Node) bool {
+			if n.Kind == KindEmoji {
+				return true
+			}
+			for _, c := range n.Children {
+				if walk(c) {
+					return true
+				}
+			}
+			return false
+		}
+		if walk(Parse(text)) {
+			t.Errorf("Parse(%q) has an emoji", text)
+		}
+	}
+}
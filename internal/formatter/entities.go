@@ -0,0 +1,247 @@
+package formatter
+
+import (
+	"strconv"
+	"strings"
+)
+
+// Entity marks a span of plain text as formatted, in the way of Telegram's
+// MessageEntity. Offset and Length count UTF-16 code units, so text with
+// Cyrillic or emoji gets the positions Telegram expects.
+type Entity struct {
+	// Type is the Telegram entity type: bold, italic, strikethrough,
+	// spoiler, code, pre, text_link or blockquote.
+	Type   string
+	Offset int
+	Length int
+	// URL is the target of a text_link.
+	URL string
+	// Language is the language of a pre block.
+	Language string
+}
+
+// EntityRenderer renders documents as plain text plus a list of entities,
+// which Telegram accepts instead of markup. Nothing has to be escaped, so
+// any text comes out exactly as written.
+//
+// Headings become bold, subtext italic and code blocks and tables pre
+// entities; quotes become blockquote entities, list items get •, ◦ and ▪
+// bullets by depth and rules are drawn with box-drawing characters. User
+// mentions with IDs from Directory become text_link entities to the user.
+type EntityRenderer struct {
+	Directory Directory
+}
+
+// Render returns the text of doc without its entities.
+func (r EntityRenderer) Render(doc *Node) string {
+	text, _ := r.RenderEntities(doc)
+	return text
+}
+
+// RenderEntities returns the text of doc and the entities that format it.
+func (r EntityRenderer) RenderEntities(doc *Node) (string, []Entity) {
+	f := r.blocks(doc.Children)
+	return f.text, f.entities
+}
+
+// fragment is a piece of rendered text with entities relative to its start.
+type fragment struct {
+	text     string
+	entities []Entity
+}
+
+func (f *fragment) add(other fragment) {
+	shift := textLength(f.text)
+	f.text += other.text
+	for _, e := range other.entities {
+		e.Offset += shift
+		f.entities = append(f.entities, e)
+	}
+}
+
+// wrap returns f with an entity of the given type covering all of it.
+func (f fragment) wrap(entity Entity) fragment {
+	entity.Length = textLength(f.text)
+	if entity.Length == 0 {
+		return f
+	}
+	f.entities = append([]Entity{entity}, f.entities...)
+	return f
+}
+
+// prefixLines prefixes every line of f, keeping its entities on the same
+// characters. prefix is given the index and the text of each line.
+func (f fragment) prefixLines(prefix func(i int, line string) string) fragment {
+	lines := strings.Split(f.text, "\n")
+
+	// starts[i] and shifts[i] are the offset of line i before prefixing and
+	// how far it moves.
+	starts := make([]int, len(lines))
+	shifts := make([]int, len(lines))
+
+	var b strings.Builder
+	offset, shift := 0, 0
+	for i, line := range lines {
+		if i > 0 {
+			b.WriteString("\n")
+		}
+		p := prefix(i, line)
+		b.WriteString(p)
+		b.WriteString(line)
+
+		shift += textLength(p)
+		starts[i], shifts[i] = offset, shift
+		offset += textLength(line) + 1
+	}
+
+	lineShift := func(pos int) int {
+		i := len(starts) - 1
+		for i > 0 && starts[i] > pos {
+			i--
+		}
+		return shifts[i]
+	}
+
+	out := fragment{text: b.String()}
+	for _, e := range f.entities {
+		end := e.Offset + e.Length
+		e.Offset += lineShift(e.Offset)
+		e.Length = end - 1 + lineShift(end-1) + 1 - e.Offset
+		out.entities = append(out.entities, e)
+	}
+	return out
+}
+
+func (r EntityRenderer) blocks(blocks []*Node) fragment {
+	var f fragment
+	for i, block := range blocks {
+		if i > 0 {
+			f.add(fragment{text: "\n\n"})
+		}
+		f.add(r.block(block))
+	}
+	return f
+}
+
+func (r EntityRenderer) block(n *Node) fragment {
+	switch n.Kind {
+	case KindCodeBlock:
+		return fragment{text: n.Literal}.wrap(Entity{Type: "pre", Language: n.Info})
+	case KindTable:
+		return fragment{text: renderTableText(n)}.wrap(Entity{Type: "pre"})
+	case KindHeading:
+		return r.inlines(n.WithoutStrong()).wrap(Entity{Type: "bold"})
+	case KindSubtext:
+		return r.inlines(n).wrap(Entity{Type: "italic"})
+	case KindList:
+		return r.list(n, 0)
+	case KindBlockQuote:
+		// Telegram does not nest quotes, so inner ones are flattened.
+		var flatten func(*Node) []*Node
+		flatten = func(n *Node) []*Node {
+			var blocks []*Node
+			for _, child := range n.Children {
+				if child.Kind == KindBlockQuote {
+					blocks = append(blocks, flatten(child)...)
+				} else {
+					blocks = append(blocks, child)
+				}
+			}
+			return blocks
+		}
+		return r.blocks(flatten(n)).wrap(Entity{Type: "blockquote"})
+	case KindThematicBreak:
+		return fragment{text: thematicBreak}
+	default:
+		return r.inlines(n)
+	}
+}
+
+// list lays a list out the way renderList does for the markup renderers.
+func (r EntityRenderer) list(list *Node, depth int) fragment {
+	indent := strings.Repeat(entityListIndent, depth)
+
+	var f fragment
+	number := list.Start
+	for i, item := range list.Children {
+		marker := glyphBullet(depth)
+		if list.Ordered {
+			marker = strconv.Itoa(number) + "."
+			number++
+		}
+
+		if i > 0 {
+			f.add(fragment{text: "\n"})
+		}
+		if len(item.Children) == 0 {
+			f.add(fragment{text: indent + marker})
+			continue
+		}
+
+		for j, child := range item.Children {
+			if j > 0 {
+				f.add(fragment{text: "\n"})
+			}
+			if child.Kind == KindList {
+				f.add(r.list(child, depth+1))
+				continue
+			}
+
+			f.add(r.block(child).prefixLines(func(k int, line string) string {
+				switch {
+				case j == 0 && k == 0:
+					return indent + marker + " "
+				case line == "":
+					return ""
+				default:
+					return indent + entityListIndent
+				}
+			}))
+		}
+	}
+	return f
+}
+
+const entityListIndent = "    "
+
+func (r EntityRenderer) inlines(n *Node) fragment {
+	var f fragment
+	for _, child := range n.Children {
+		f.add(r.inline(child))
+	}
+	return f
+}
+
+func (r EntityRenderer) inline(n *Node) fragment {
+	switch n.Kind {
+	case KindText:
+		return fragment{text: n.Literal}
+	case KindSoftBreak:
+		return fragment{text: "\n"}
+	case KindCode:
+		return fragment{text: n.Literal}.wrap(Entity{Type: "code"})
+	case KindStrong:
+		return r.inlines(n).wrap(Entity{Type: "bold"})
+	case KindEmphasis:
+		return r.inlines(n).wrap(Entity{Type: "italic"})
+	case KindStrikethrough:
+		return r.inlines(n).wrap(Entity{Type: "strikethrough"})
+	case KindSpoiler:
+		return r.inlines(n).wrap(Entity{Type: "spoiler"})
+	case KindMention:
+		if id, ok := r.Directory.lookup(n); ok && n.Info == MentionUser {
+			return fragment{text: n.Literal}.wrap(Entity{Type: "text_link", URL: telegramUserURL(id)})
+		}
+		return fragment{text: n.PlainText()}
+	case KindEmoji:
+		return fragment{text: n.PlainText()}
+	case KindLink:
+		if n.PlainText() == n.URL {
+			// Bare URLs are linked by Telegram itself.
+			return fragment{text: n.URL}
+		}
+		return r.inlines(n).wrap(Entity{Type: "text_link", URL: n.URL})
+	default:
+		return r.inlines(n)
+	}
+}
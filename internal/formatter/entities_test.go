@@ -0,0 +1,87 @@
+package formatter
+
+import (
+	"testing"
+	"unicode/utf16"
+)
+
+func TestRenderEntitiesOffsets(t *testing.T) {
+	// Offsets and lengths count UTF-16 code units: a Cyrillic letter is one,
+	// an emoji outside the BMP two.
+	tests := []struct {
+		name     string
+		markdown string
+		text     string
+		want     []Entity // with Offset and Length
+		covers   []string // the text each entity covers
+	}{
+		{
+			name:     "cyrillic",
+			markdown: "Привіт **світ**",
+			text:     "Привіт світ",
+			want:     []Entity{{Type: "bold", Offset: 7, Length: 4}},
+			covers:   []string{"світ"},
+		},
+		{
+			name:     "emoji before",
+			markdown: "😀 **bold** 😀",
+			text:     "😀 bold 😀",
+			want:     []Entity{{Type: "bold", Offset: 3, Length: 4}},
+			covers:   []string{"bold"},
+		},
+		{
+			name:     "emoji inside",
+			markdown: "a *👍🏽 так* b",
+			text:     "a 👍🏽 так b",
+			want:     []Entity{{Type: "italic", Offset: 2, Length: 8}},
+			covers:   []string{"👍🏽 так"},
+		},
+		{
+			name:     "nested",
+			markdown: "**жирний _і 🙂 курсив_**",
+			text:     "жирний і 🙂 курсив",
+			want:     []Entity{{Type: "bold", Offset: 0, Length: 18}, {Type: "italic", Offset: 7, Length: 11}},
+			covers:   []string{"жирний і 🙂 курсив", "і 🙂 курсив"},
+		},
+		{
+			name:     "link after emoji",
+			markdown: "🚀 [реліз](https://example.com)",
+			text:     "🚀 реліз",
+			want:     []Entity{{Type: "text_link", Offset: 3, Length: 5, URL: "https://example.com"}},
+			covers:   []string{"реліз"},
+		},
+		{
+			name:     "list bullets",
+			markdown: "- 🙂 перший\n- **другий**",
+			text:     "• 🙂 перший\n• другий",
+			want:     []Entity{{Type: "bold", Offset: 14, Length: 6}},
+			covers:   []string{"другий"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			text, entities := EntityRenderer{}.RenderEntities(Parse(tt.markdown))
+			if text != tt.text {
+				t.Fatalf("text = %q, want %q", text, tt.text)
+			}
+			if len(entities) != len(tt.want) {
+				t.Fatalf("entities = %+v, want %+v", entities, tt.want)
+			}
+
+			units := utf16.Encode([]rune(text))
+			for i, e := range entities {
+				if e != tt.want[i] {
+					t.Errorf("entity %d = %+v, want %+v", i, e, tt.want[i])
+				}
+				if e.Offset+e.Length > len(units) {
+					t.Errorf("entity %d ends at %d, past the text", i, e.Offset+e.Length)
+					continue
+				}
+				if got := string(utf16.Decode(units[e.Offset : e.Offset+e.Length])); got != tt.covers[i] {
+					t.Errorf("entity %d covers %q, want %q", i, got, tt.covers[i])
+				}
+			}
+		})
+	}
+}
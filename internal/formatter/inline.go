@@ -0,0 +1,454 @@
+package formatter
+
+import (
+	"regexp"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// delimiter is a run of emphasis characters (*, _, ~ or the || of a spoiler)
+// waiting to be matched with a run of the same character.
+type delimiter struct {
+	char     byte
+	count    int // characters not yet used by a match
+	original int // length of the run as written
+	canOpen  bool
+	canClose bool
+}
+
+// inlineItem is either a finished node or a pending delimiter run.
+type inlineItem struct {
+	node  *Node
+	delim *delimiter
+}
+
+var autolinkRe = regexp.MustCompile(`^<([a-zA-Z][a-zA-Z0-9+.-]{1,31}:[^<>\s|]*)>`)
+
+// parseInlines parses the inline content of a block. Emphasis is resolved
+// with the CommonMark delimiter rules, so nested and mixed markers such as
+// ***bold italic*** or *a **b** c* and stray asterisks in text like 2 * 3
+// come out as written.
+func parseInlines(text string) []*Node {
+	var items []inlineItem
+	var buf strings.Builder
+
+	flushText := func() {
+		if buf.Len() > 0 {
+			items = append(items, inlineItem{node: &Node{Kind: KindText, Literal: buf.String()}})
+			buf.Reset()
+		}
+	}
+	push := func(n *Node) {
+		flushText()
+		items = append(items, inlineItem{node: n})
+	}
+
+	for i := 0; i < len(text); {
+		c := text[i]
+
+		switch {
+		case c == 'h' || c == 'w':
+			// Bare URLs are taken whole, so the _ or * in them do not start
+			// emphasis and an @ in them is not a mention.
+			if node, next, ok := parseURL(text, i); ok {
+				push(node)
+				i = next
+				continue
+			}
+			buf.WriteByte(c)
+			i++
+
+		case c == '\\' && i+1 < len(text) && isASCIIPunct(text[i+1]):
+			buf.WriteByte(text[i+1])
+			i += 2
+
+		case c == '`':
+			if node, next, ok := parseCodeSpan(text, i); ok {
+				push(node)
+				i = next
+				continue
+			}
+			// An unmatched backtick run is literal text.
+			n := runLength(text, i)
+			buf.WriteString(text[i : i+n])
+			i += n
+
+		case c == '[':
+			if node, next, ok := parseLink(text, i); ok {
+				push(node)
+				i = next
+				continue
+			}
+			buf.WriteByte(c)
+			i++
+
+		case c == '<':
+			if m := autolinkRe.FindStringSubmatch(text[i:]); m != nil {
+				push(&Node{Kind: KindLink, URL: m[1], Children: []*Node{{Kind: KindText, Literal: m[1]}}})
+				i += len(m[0])
+				continue
+			}
+			buf.WriteByte(c)
+			i++
+
+		case c == ':':
+			if node, next, ok := parseEmoji(text, i); ok {
+				push(node)
+				i = next
+				continue
+			}
+			buf.WriteByte(c)
+			i++
+
+		case c == '@':
+			if node, next, ok := parseMention(text, i); ok {
+				push(node)
+				i = next
+				continue
+			}
+			buf.WriteByte(c)
+			i++
+
+		case c == '*' || c == '_' || c == '~' || c == '|':
+			n := runLength(text, i)
+			if (c == '~' && n > 2) || (c == '|' && n != 2) {
+				buf.WriteString(text[i : i+n])
+				i += n
+				continue
+			}
+			flushText()
+			items = append(items, inlineItem{delim: newDelimiter(text, i, n)})
+			i += n
+
+		case c == '\n':
+			push(&Node{Kind: KindSoftBreak})
+			i++
+
+		default:
+			buf.WriteByte(c)
+			i++
+		}
+	}
+	flushText()
+
+	items = processEmphasis(items)
+	return mergeText(itemsToNodes(items))
+}
+
+// newDelimiter classifies the run of n characters at text[i:] as a potential
+// opener and/or closer following the CommonMark flanking rules.
+func newDelimiter(text string, i, n int) *delimiter {
+	before, after := ' ', ' '
+	if i > 0 {
+		before, _ = utf8.DecodeLastRuneInString(text[:i])
+	}
+	if i+n < len(text) {
+		after, _ = utf8.DecodeRuneInString(text[i+n:])
+	}
+
+	leftFlanking := !unicode.IsSpace(after) &&
+		(!isPunct(after) || unicode.IsSpace(before) || isPunct(before))
+	rightFlanking := !unicode.IsSpace(before) &&
+		(!isPunct(before) || unicode.IsSpace(after) || isPunct(after))
+
+	d := &delimiter{char: text[i], count: n, original: n}
+	if d.char == '_' {
+		// Underscores inside words (snake_case) never emphasise.
+		d.canOpen = leftFlanking && (!rightFlanking || isPunct(before))
+		d.canClose = rightFlanking && (!leftFlanking || isPunct(after))
+	} else {
+		d.canOpen = leftFlanking
+		d.canClose = rightFlanking
+	}
+
+	return d
+}
+
+// processEmphasis matches closers with the nearest compatible openers and
+// replaces each matched pair and the items between them with an emphasis
+// node.
+func processEmphasis(items []inlineItem) []inlineItem {
+	for i := 0; i < len(items); {
+		closer := items[i].delim
+		if closer == nil || !closer.canClose || closer.count == 0 {
+			i++
+			continue
+		}
+
+		opener := -1
+		for j := i - 1; j >= 0; j-- {
+			d := items[j].delim
+			if d != nil && d.canOpen && d.count > 0 && matches(d, closer) {
+				opener = j
+				break
+			}
+		}
+		if opener < 0 {
+			i++
+			continue
+		}
+
+		open := items[opener].delim
+		kind, use := KindEmphasis, 1
+		switch {
+		case closer.char == '~':
+			kind, use = KindStrikethrough, closer.count
+		case closer.char == '|':
+			kind, use = KindSpoiler, 2
+		case open.count >= 2 && closer.count >= 2:
+			kind, use = KindStrong, 2
+		}
+		open.count -= use
+		closer.count -= use
+
+		node := &Node{Kind: kind, Children: mergeText(itemsToNodes(items[opener+1 : i]))}
+
+		rest := append([]inlineItem{{node: node}}, items[i:]...)
+		items = append(items[:opener+1], rest...)
+		i = opener + 2
+
+		if open.count == 0 {
+			items = append(items[:opener], items[opener+1:]...)
+			i--
+		}
+		if closer.count == 0 {
+			items = append(items[:i], items[i+1:]...)
+		}
+	}
+
+	return items
+}
+
+// matches reports whether opener can be closed by closer.
+func matches(opener, closer *delimiter) bool {
+	if opener.char != closer.char {
+		return false
+	}
+	if opener.char == '~' || opener.char == '|' {
+		return opener.count == closer.count
+	}
+
+	// The "rule of 3": a run that can both open and close only matches when
+	// the lengths do not add up to a multiple of 3, so that **a*b** parses
+	// as intended.
+	if (opener.canClose || closer.canOpen) &&
+		(opener.original+closer.original)%3 == 0 &&
+		!(opener.original%3 == 0 && closer.original%3 == 0) {
+		return false
+	}
+
+	return true
+}
+
+// itemsToNodes turns unmatched delimiters back into text.
+func itemsToNodes(items []inlineItem) []*Node {
+	nodes := make([]*Node, 0, len(items))
+	for _, item := range items {
+		if item.node != nil {
+			nodes = append(nodes, item.node)
+		} else if item.delim.count > 0 {
+			literal := strings.Repeat(string(item.delim.char), item.delim.count)
+			nodes = append(nodes, &Node{Kind: KindText, Literal: literal})
+		}
+	}
+	return nodes
+}
+
+// mergeText joins adjacent text nodes.
+func mergeText(nodes []*Node) []*Node {
+	merged := nodes[:0]
+	for _, n := range nodes {
+		if n.Kind == KindText && len(merged) > 0 && merged[len(merged)-1].Kind == KindText {
+			last := merged[len(merged)-1]
+			merged[len(merged)-1] = &Node{Kind: KindText, Literal: last.Literal + n.Literal}
+			continue
+		}
+		merged = append(merged, n)
+	}
+	return merged
+}
+
+// parseCodeSpan parses a code span opened by the backtick run at text[i:].
+func parseCodeSpan(text string, i int) (*Node, int, bool) {
+	n := runLength(text, i)
+	start := i + n
+
+	for j := start; j < len(text); {
+		if text[j] != '`' {
+			j++
+			continue
+		}
+
+		m := runLength(text, j)
+		if m != n {
+			j += m
+			continue
+		}
+
+		content := strings.ReplaceAll(text[start:j], "\n", " ")
+		if len(content) > 1 && content[0] == ' ' && content[len(content)-1] == ' ' && strings.Trim(content, " ") != "" {
+			content = content[1 : len(content)-1]
+		}
+		return &Node{Kind: KindCode, Literal: content}, j + m, true
+	}
+
+	return nil, 0, false
+}
+
+// parseLink parses an inline link [text](url "title") at text[i:].
+func parseLink(text string, i int) (*Node, int, bool) {
+	closeBracket := findClosingBracket(text, i)
+	if closeBracket < 0 || closeBracket+1 >= len(text) || text[closeBracket+1] != '(' {
+		return nil, 0, false
+	}
+
+	depth := 0
+	end := -1
+	for j := closeBracket + 2; j < len(text) && end < 0; j++ {
+		switch text[j] {
+		case '\\':
+			j++
+		case '(':
+			depth++
+		case ')':
+			if depth == 0 {
+				end = j
+			}
+			depth--
+		case '\n':
+			return nil, 0, false
+		}
+	}
+	if end < 0 {
+		return nil, 0, false
+	}
+
+	dest := strings.TrimSpace(text[closeBracket+2 : end])
+	if k := strings.IndexAny(dest, " \t"); k >= 0 {
+		// Drop the optional link title.
+		dest = dest[:k]
+	}
+	dest = strings.TrimSuffix(strings.TrimPrefix(dest, "<"), ">")
+	if dest == "" {
+		return nil, 0, false
+	}
+
+	return &Node{
+		Kind:     KindLink,
+		URL:      unescapePunct(dest),
+		Children: parseInlines(text[i+1 : closeBracket]),
+	}, end + 1, true
+}
+
+// parseURL parses a bare URL at text[i:], an autolink literal in GFM terms:
+// http://, https:// or www. at the start of a word, up to the next space or
+// <. Trailing punctuation, and a ) that does not close a ( of the URL, are
+// left out as the end of the sentence.
+func parseURL(text string, i int) (*Node, int, bool) {
+	if i > 0 {
+		before, _ := utf8.DecodeLastRuneInString(text[:i])
+		if !unicode.IsSpace(before) && !strings.ContainsRune("*_~(", before) {
+			return nil, 0, false
+		}
+	}
+
+	rest := text[i:]
+	var prefix string
+	for _, p := range []string{"http://", "https://", "www."} {
+		if strings.HasPrefix(rest, p) {
+			prefix = p
+		}
+	}
+	if prefix == "" {
+		return nil, 0, false
+	}
+
+	end := strings.IndexFunc(rest, func(r rune) bool { return unicode.IsSpace(r) || r == '<' })
+	if end < 0 {
+		end = len(rest)
+	}
+	literal := trimURL(rest[:end])
+	if len(literal) <= len(prefix) || isASCIIPunct(literal[len(prefix)]) {
+		return nil, 0, false
+	}
+
+	url := literal
+	if prefix == "www." {
+		url = "http://" + literal
+	}
+	return &Node{Kind: KindLink, URL: url, Children: []*Node{{Kind: KindText, Literal: literal}}}, i + len(literal), true
+}
+
+// trimURL drops the punctuation that ends the sentence around a bare URL.
+func trimURL(url string) string {
+	for url != "" {
+		last := url[len(url)-1]
+		switch {
+		case strings.IndexByte("?!.,:;*_~'\"", last) >= 0:
+			url = url[:len(url)-1]
+		case last == ')' && strings.Count(url, ")") > strings.Count(url, "("):
+			url = url[:len(url)-1]
+		default:
+			return url
+		}
+	}
+	return url
+}
+
+// findClosingBracket returns the index of the ] matching the [ at text[i],
+// skipping code spans and escaped brackets, or -1.
+func findClosingBracket(text string, i int) int {
+	depth := 0
+	for j := i; j < len(text); j++ {
+		switch text[j] {
+		case '\\':
+			j++
+		case '`':
+			if _, next, ok := parseCodeSpan(text, j); ok {
+				j = next - 1
+			} else {
+				j += runLength(text, j) - 1
+			}
+		case '[':
+			depth++
+		case ']':
+			depth--
+			if depth == 0 {
+				return j
+			}
+		}
+	}
+	return -1
+}
+
+func unescapePunct(s string) string {
+	if !strings.Contains(s, `\`) {
+		return s
+	}
+
+	var b strings.Builder
+	for i := 0; i < len(s); i++ {
+		if s[i] == '\\' && i+1 < len(s) && isASCIIPunct(s[i+1]) {
+			i++
+		}
+		b.WriteByte(s[i])
+	}
+	return b.String()
+}
+
+func runLength(text string, i int) int {
+	n := 0
+	for i+n < len(text) && text[i+n] == text[i] {
+		n++
+	}
+	return n
+}
+
+func isASCIIPunct(c byte) bool {
+	return c < utf8.RuneSelf && strings.IndexByte("!\"#$%&'()*+,-./:;<=>?@[\\]^_`{|}~", c) >= 0
+}
+
+func isPunct(r rune) bool {
+	return unicode.IsPunct(r) || unicode.IsSymbol(r)
+}
@@ -0,0 +1,162 @@
+package formatter
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestParseURL(t *testing.T) {
+	tests := []struct {
+		name string
+		text string
+		url  string // "" when the text holds no link
+		rest string // the text after the link
+	}{
+		{name: "underscores", text: "see https://example.com/a_b_c ok", url: "https://example.com/a_b_c", rest: " ok"},
+		{name: "asterisks", text: "see https://example.com/*x*/**y**/z ok", url: "https://example.com/*x*/**y**/z", rest: " ok"},
+		{name: "tildes", text: "see https://example.com/~~x~~/y ok", url: "https://example.com/~~x~~/y", rest: " ok"},
+		{name: "at sign", text: "see https://github.com/@here ok", url: "https://github.com/@here", rest: " ok"},
+		{name: "colons", text: "see http://example.com:8080/:tada: ok", url: "http://example.com:8080/:tada", rest: ": ok"},
+		{name: "www", text: "see www.example.com/a_b", url: "http://www.example.com/a_b"},
+		{name: "trailing punctuation", text: "see https://example.com/x.", url: "https://example.com/x", rest: "."},
+		{name: "closing parenthesis", text: "(see https://example.com/x)", url: "https://example.com/x", rest: ")"},
+		{name: "parentheses in URL", text: "see https://example.com/Go_(lang)", url: "https://example.com/Go_(lang)"},
+		{name: "inside a word", text: "xhttps://example.com/a_b"},
+		{name: "no host", text: "see https:// and www."},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			inlines := Parse(tt.text).Children[0].Children
+
+			var link *Node
+			rest := ""
+			for _, n := range inlines {
+				switch {
+				case n.Kind == KindLink:
+					link = n
+				case link != nil:
+					rest += n.PlainText()
+				}
+			}
+
+			if tt.url == "" {
+				if link != nil {
+					t.Fatalf("Parse(%q) has a link to %q, want none", tt.text, link.URL)
+				}
+				return
+			}
+			if link == nil {
+				t.Fatalf("Parse(%q) has no link", tt.text)
+			}
+			if link.URL != tt.url {
+				t.Errorf("URL = %q, want %q", link.URL, tt.url)
+			}
+			if rest != tt.rest {
+				t.Errorf("text after the link = %q, want %q", rest, tt.rest)
+			}
+		})
+	}
+}
+
+func TestRenderURL(t *testing.T) {
+	text := "see https://example.com/a_b*c*~~d~~e and _e_"
+
+	tests := []struct {
+		name     string
+		renderer Renderer
+		want     string
+	}{
+		{"slack", SlackRenderer{}, "see <https://example.com/a_b*c*~~d~~e> and _e_"},
+		{"telegram", TelegramRenderer{}, `see https://example\.com/a\_b\*c\*\~\~d\~\~e and _e_`},
+		{"telegram-html", TelegramHTMLRenderer{}, "see https://example.com/a_b*c*~~d~~e and <i>e</i>"},
+		{"discord", DiscordRenderer{}, "see https://example.com/a_b*c*~~d~~e and *e*"},
+		{"plain", PlainRenderer{}, "see https://example.com/a_b*c*~~d~~e and e"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := Render(text, tt.renderer); got != tt.want {
+				t.Errorf("Render(%q) = %q, want %q", text, got, tt.want)
+			}
+		})
+	}
+}
+
+// inlineTree writes the inline nodes as kind(children), for comparing
+// parse trees.
+func inlineTree(nodes []*Node) string {
+	names := map[NodeKind]string{
+		KindEmphasis:      "em",
+		KindStrong:        "strong",
+		KindStrikethrough: "strike",
+		KindSpoiler:       "spoiler",
+	}
+
+	var b strings.Builder
+	for _, n := range nodes {
+		if name, ok := names[n.Kind]; ok {
+			b.WriteString(name + "(" + inlineTree(n.Children) + ")")
+		} else {
+			b.WriteString(n.PlainText())
+		}
+	}
+	return b.String()
+}
+
+func TestParseEmphasis(t *testing.T) {
+	tests := []struct {
+		text string
+		want string
+	}{
+		{"*italic* and _italic_", "em(italic) and em(italic)"},
+		{"**bold** and __bold__", "strong(bold) and strong(bold)"},
+		{"***bold italic***", "em(strong(bold italic))"},
+		{"___bold italic___", "em(strong(bold italic))"},
+		{"***a** b*", "em(strong(a) b)"},
+		{"***a* b**", "strong(em(a) b)"},
+		{"*a **b** c*", "em(a strong(b) c)"},
+		{"**a *b* c**", "strong(a em(b) c)"},
+		{"*foo**bar**baz*", "em(foostrong(bar)baz)"},
+		{"**a _b_ c**", "strong(a em(b) c)"},
+		{"~~struck *x*~~ ||hidden **y**||", "strike(struck em(x)) spoiler(hidden strong(y))"},
+		{"2 * 3 * 4", "2 * 3 * 4"},
+		{"snake_case_name", "snake_case_name"},
+		{"**unclosed", "**unclosed"},
+		{"*a **b c*", "*a *em(b c)"},
+		{"a ~~~not struck~~~", "a ~~~not struck~~~"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.text, func(t *testing.T) {
+			got := inlineTree(Parse(tt.text).Children[0].Children)
+			if got != tt.want {
+				t.Errorf("Parse(%q) = %s, want %s", tt.text, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestRenderBoldItalic(t *testing.T) {
+	text := "***both*** and **bold _italic_**"
+
+	tests := []struct {
+		name     string
+		renderer Renderer
+		want     string
+	}{
+		{"slack", SlackRenderer{}, "_*both*_ and *bold _italic_*"},
+		{"telegram", TelegramRenderer{}, "_*both*_ and *bold _italic_*"},
+		{"telegram-html", TelegramHTMLRenderer{}, "<i><b>both</b></i> and <b>bold <i>italic</i></b>"},
+		{"discord", DiscordRenderer{}, "***both*** and **bold *italic***"},
+		{"plain", PlainRenderer{}, "both and bold italic"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := Render(text, tt.renderer); got != tt.want {
+				t.Errorf("Render(%q) = %q, want %q", text, got, tt.want)
+			}
+		})
+	}
+}
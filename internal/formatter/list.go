@@ -0,0 +1,220 @@
+package formatter
+
+import (
+	"strconv"
+	"strings"
+)
+
+// parseBlockQuote collects the consecutive quoted lines starting at
+// lines[start] and parses their content as blocks.
+func parseBlockQuote(lines []string, start int) (*Node, int) {
+	var content []string
+
+	i := start
+	for ; i < len(lines); i++ {
+		loc := blockQuoteRe.FindStringIndex(lines[i])
+		if loc == nil {
+			break
+		}
+		content = append(content, lines[i][loc[1]:])
+	}
+
+	return &Node{Kind: KindBlockQuote, Children: parseBlocks(content)}, i
+}
+
+// listMarker describes the marker of a list item line.
+type listMarker struct {
+	bullet  byte // '-', '*' or '+' for bullet lists, '.' or ')' for ordered
+	ordered bool
+	number  int
+	indent  int // column where the item content starts
+}
+
+func parseListMarker(line string) (listMarker, bool) {
+	m := listItemRe.FindStringSubmatch(line)
+	if m == nil {
+		return listMarker{}, false
+	}
+
+	marker := listMarker{indent: len(m[0])}
+	if m[3] == "" {
+		// An empty item: content would start after one space.
+		marker.indent++
+	}
+
+	symbol := m[2]
+	if n, err := strconv.Atoi(symbol[:len(symbol)-1]); err == nil {
+		marker.ordered = true
+		marker.number = n
+		marker.bullet = symbol[len(symbol)-1]
+	} else {
+		marker.bullet = symbol[0]
+	}
+
+	return marker, true
+}
+
+// parseList parses a list starting at lines[start]. Items continue while
+// lines are indented to the item content or are lazy continuations of its
+// last paragraph; nested lists are parsed from the indented content.
+func parseList(lines []string, start int) (*Node, int, bool) {
+	first, ok := parseListMarker(lines[start])
+	if !ok {
+		return nil, 0, false
+	}
+	// A lone "-" or "*" line is a thematic break or setext underline, and
+	// "-#" is subtext; none of them start a list.
+	if thematicBreakRe.MatchString(lines[start]) {
+		return nil, 0, false
+	}
+
+	list := &Node{Kind: KindList, Ordered: first.ordered, Start: first.number}
+
+	i := start
+	for i < len(lines) {
+		marker, ok := parseListMarker(lines[i])
+		if !ok || marker.ordered != first.ordered || marker.bullet != first.bullet || thematicBreakRe.MatchString(lines[i]) {
+			break
+		}
+
+		content := []string{strings.TrimLeft(lines[i][min(marker.indent, len(lines[i])):], " ")}
+		i++
+
+		for i < len(lines) {
+			line := lines[i]
+			switch {
+			case isBlank(line):
+				// A blank line belongs to the item only if the item goes on
+				// after it.
+				next := i + 1
+				for next < len(lines) && isBlank(lines[next]) {
+					next++
+				}
+				if next >= len(lines) || leadingSpaces(lines[next]) < marker.indent {
+					goto itemDone
+				}
+				content = append(content, "")
+			case leadingSpaces(line) >= marker.indent:
+				content = append(content, stripIndent(line, marker.indent))
+			case !startsBlock(line) && !isBlank(content[len(content)-1]):
+				// Lazy continuation of the item's paragraph.
+				content = append(content, strings.TrimLeft(line, " \t"))
+			default:
+				goto itemDone
+			}
+			i++
+		}
+
+	itemDone:
+		list.Children = append(list.Children, &Node{Kind: KindListItem, Children: parseBlocks(content)})
+
+		// Blank lines between items keep the list going.
+		next := i
+		for next < len(lines) && isBlank(lines[next]) {
+			next++
+		}
+		if next < len(lines) {
+			if m, ok := parseListMarker(lines[next]); ok && m.ordered == first.ordered && m.bullet == first.bullet {
+				i = next
+			}
+		}
+	}
+
+	return list, i, true
+}
+
+// listStyle describes how a renderer lays lists out.
+type listStyle struct {
+	// bullet returns the glyph of unordered items at a nesting depth.
+	bullet func(depth int) string
+	// number returns the marker of the n-th ordered item.
+	number func(n int) string
+	// indent is the indentation of each nesting level.
+	indent string
+	// quote, if set, is a quote marker that only counts at the start of a
+	// line. The lines of a quote in an item then start with it, and the item
+	// indentation follows.
+	quote string
+}
+
+// renderList renders one item per line with nested lists indented below
+// their item. Continuation lines of an item are indented to its content.
+func renderList(list *Node, depth int, style listStyle, block func(*Node) string) string {
+	var lines []string
+	prefix := strings.Repeat(style.indent, depth)
+
+	number := list.Start
+	for _, item := range list.Children {
+		marker := style.bullet(depth)
+		if list.Ordered {
+			marker = style.number(number)
+			number++
+		}
+
+		if len(item.Children) == 0 {
+			lines = append(lines, prefix+marker)
+			continue
+		}
+
+		for j, child := range item.Children {
+			if child.Kind == KindList {
+				lines = append(lines, renderList(child, depth+1, style, block))
+				continue
+			}
+
+			for k, line := range strings.Split(block(child), "\n") {
+				quote := ""
+				if child.Kind == KindBlockQuote && style.quote != "" && strings.HasPrefix(line, style.quote) {
+					quote, line = style.quote, strings.TrimPrefix(line, style.quote)
+				}
+
+				if j == 0 && k == 0 {
+					lines = append(lines, quote+prefix+marker+" "+line)
+				} else if line == "" {
+					lines = append(lines, quote)
+				} else {
+					lines = append(lines, quote+prefix+style.indent+line)
+				}
+			}
+		}
+	}
+
+	return strings.Join(lines, "\n")
+}
+
+// bulletGlyphs are the normalised bullets of nested unordered lists.
+var bulletGlyphs = []string{"•", "◦", "▪"}
+
+func glyphBullet(depth int) string {
+	return bulletGlyphs[depth%len(bulletGlyphs)]
+}
+
+// prefixLines prefixes every line of text, as quotes are written.
+func prefixLines(text, prefix, blankPrefix string) string {
+	lines := strings.Split(text, "\n")
+	for i, line := range lines {
+		if line == "" {
+			lines[i] = blankPrefix
+		} else {
+			lines[i] = prefix + line
+		}
+	}
+	return strings.Join(lines, "\n")
+}
+
+// thematicBreak is drawn as a line on platforms without a native rule.
+const thematicBreak = "──────────"
+
+// renderQuote renders the content of a block quote with every line
+// prefixed. None of the platforms nest quotes, so inner quotes are flattened
+// into the outer one.
+func renderQuote(quote *Node, prefix, blankPrefix string, block func(*Node) string) string {
+	var flatten func(*Node) string
+	flatten = func(n *Node) string {
+		if n.Kind == KindBlockQuote {
+			return joinBlocks(n.Children, flatten)
+		}
+		return block(n)
+	}
+	return prefixLines(joinBlocks(quote.Children, flatten), prefix, blankPrefix)
+}
@@ -0,0 +1,65 @@
+package formatter
+
+import (
+	"regexp"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// Mention types, stored in the Info of a mention node.
+const (
+	MentionUser     = "user"
+	MentionGroup    = "group"
+	MentionHere     = "here"
+	MentionChannel  = "channel"
+	MentionEveryone = "everyone"
+)
+
+// mentionRe matches the platform-neutral mention syntax: @user:alice,
+// @group:sre, @here, @channel and @everyone.
+var mentionRe = regexp.MustCompile(`^@(?:(user|group):([A-Za-z0-9](?:[A-Za-z0-9._-]*[A-Za-z0-9])?)|(here|channel|everyone)\b)`)
+
+// parseMention parses a mention at text[i:]. A mention starts a word: an
+// @ that follows a word character or a /, as in an e-mail address or a
+// path, does not start one.
+func parseMention(text string, i int) (*Node, int, bool) {
+	if i > 0 {
+		before, _ := utf8.DecodeLastRuneInString(text[:i])
+		if unicode.IsLetter(before) || unicode.IsDigit(before) || strings.ContainsRune("._-/", before) {
+			return nil, 0, false
+		}
+	}
+
+	m := mentionRe.FindStringSubmatch(text[i:])
+	if m == nil {
+		return nil, 0, false
+	}
+
+	if m[3] != "" {
+		return &Node{Kind: KindMention, Info: m[3], Literal: m[3]}, i + len(m[0]), true
+	}
+	return &Node{Kind: KindMention, Info: m[1], Literal: m[2]}, i + len(m[0]), true
+}
+
+// Directory maps the user and group names of mentions to the IDs of one
+// platform: member IDs for users, and user group (Slack) or role (Discord)
+// IDs for groups. Names missing from it are shown as plain @name text.
+// Emoji maps the shortcodes of Discord custom emoji to their IDs.
+type Directory struct {
+	Users  map[string]string
+	Groups map[string]string
+	Emoji  map[string]string
+}
+
+// lookup returns the ID a user or group mention refers to.
+func (d Directory) lookup(n *Node) (string, bool) {
+	var id string
+	switch n.Info {
+	case MentionUser:
+		id = d.Users[n.Literal]
+	case MentionGroup:
+		id = d.Groups[n.Literal]
+	}
+	return id, id != ""
+}
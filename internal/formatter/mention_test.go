@@ -0,0 +1,128 @@
+package formatter
+
+import "testing"
+
+func TestParseMention(t *testing.T) {
+	tests := []struct {
+		text string
+		want []string // the Info of each mention
+	}{
+		{"@here", []string{MentionHere}},
+		{"ping @channel, @everyone and @user:alice", []string{MentionChannel, MentionEveryone, MentionUser}},
+		{"(@group:sre) **@here**", []string{MentionGroup, MentionHere}},
+		{"@heretic and @user:", nil},
+		{"mail me@here or me.x@user:alice", nil},
+		{"see https://github.com/@here", nil},
+		{"see <https://github.com/@here>", nil},
+		{"a path/@here", nil},
+		{"привіт@here", nil},
+		{"`@here`", nil},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.text, func(t *testing.T) {
+			var got []string
+			var walk func(n *Node)
+			walk = func(n *Node) {
+				if n.Kind == KindMention {
+					got = append(got, n.Info)
+				}
+				for _, c := range n.Children {
+					walk(c)
+				}
+			}
+			walk(Parse(tt.text))
+
+			if len(got) != len(tt.want) {
+				t.Fatalf("Parse(%q) has mentions %q, want %q", tt.text, got, tt.want)
+			}
+			for i := range got {
+				if got[i] != tt.want[i] {
+					t.Errorf("mention %d = %q, want %q", i, got[i], tt.want[i])
+				}
+			}
+		})
+	}
+}
+
+func TestRenderMentionInURL(t *testing.T) {
+	text := "see https://github.com/@here"
+	if got, want := Render(text, SlackRenderer{}), "see <https://github.com/@here>"; got != want {
+		t.Errorf("Render(%q) = %q, want %q", text, got, want)
+	}
+}
+
+var testDirectory = Directory{
+	Users:  map[string]string{"alice": "U1"},
+	Groups: map[string]string{"sre": "S1"},
+	Emoji:  map[string]string{"party_blob": "123"},
+}
+
+func TestRenderMentions(t *testing.T) {
+	text := "@user:alice @user:bob @group:sre @here @channel @everyone"
+
+	tests := []struct {
+		name     string
+		renderer Renderer
+		want     string
+	}{
+		{"slack", SlackRenderer{Directory: testDirectory}, "<@U1> @bob <!subteam^S1> <!here> <!channel> <!everyone>"},
+		{"discord", DiscordRenderer{Directory: testDirectory}, "<@U1> @bob <@&S1> @here @here @everyone"},
+		{"telegram", TelegramRenderer{Directory: testDirectory}, "[alice](tg://user?id=U1) @bob @sre @here @channel @everyone"},
+		{"telegram-html", TelegramHTMLRenderer{Directory: testDirectory}, `<a href="tg://user?id=U1">alice</a> @bob @sre @here @channel @everyone`},
+		{"entities", EntityRenderer{Directory: testDirectory}, "alice @bob @sre @here @channel @everyone"},
+		{"plain", PlainRenderer{}, "@alice @bob @sre @here @channel @everyone"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := Render(text, tt.renderer); got != tt.want {
+				t.Errorf("Render(%q) = %q, want %q", text, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestRenderMentionEntity(t *testing.T) {
+	_, entities := EntityRenderer{Directory: testDirectory}.RenderEntities(Parse("hi @user:alice"))
+	want := Entity{Type: "text_link", Offset: 3, Length: 5, URL: "tg://user?id=U1"}
+	if len(entities) != 1 || entities[0] != want {
+		t.Errorf("entities = %+v, want [%+v]", entities, want)
+	}
+}
+
+func TestSafeMentions(t *testing.T) {
+	// Mass mentions, written either way, reach nobody; user and group
+	// mentions still work.
+	text := "@user:alice @group:sre @here @channel @everyone <!channel> <!here|here> plain @everyone"
+
+	tests := []struct {
+		name     string
+		renderer Renderer
+		want     string
+	}{
+		{
+			"slack",
+			SlackRenderer{Directory: testDirectory, SafeMentions: true},
+			"<@U1> <!subteam^S1> @here @channel @everyone &lt;!channel&gt; &lt;!here|here&gt; plain @everyone",
+		},
+		{
+			"slack unsafe",
+			SlackRenderer{Directory: testDirectory},
+			"<@U1> <!subteam^S1> <!here> <!channel> <!everyone> &lt;!channel&gt; &lt;!here|here&gt; plain <!everyone>",
+		},
+		{
+			"discord",
+			DiscordRenderer{Directory: testDirectory, SafeMentions: true},
+			"<@U1> <@&S1> @\u200bhere @\u200bhere @\u200beveryone <!channel> <!here\\|here> plain @\u200beveryone",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := Render(text, tt.renderer); got != tt.want {
+				t.Errorf("Render(%q) = %q, want %q", text, got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,262 @@
+package formatter
+
+import (
+	"regexp"
+	"strings"
+)
+
+var (
+	headingRe       = regexp.MustCompile(`^ {0,3}(#{1,6})(?:[ \t]+(.*?))?(?:[ \t]+#+)?[ \t]*$`)
+	setextRe        = regexp.MustCompile(`^ {0,3}(=+|-+)[ \t]*$`)
+	subtextRe       = regexp.MustCompile(`^ {0,3}-#[ \t]+(.*?)[ \t]*$`)
+	thematicBreakRe = regexp.MustCompile(`^ {0,3}(?:(?:-[ \t]*){3,}|(?:\*[ \t]*){3,}|(?:_[ \t]*){3,})$`)
+	blockQuoteRe    = regexp.MustCompile(`^ {0,3}> ?`)
+	listItemRe      = regexp.MustCompile(`^( {0,3})([-*+]|\d{1,9}[.)])( {1,4}|$)`)
+)
+
+// Parse parses CommonMark-style Markdown into a document tree. Constructs the
+// parser does not know are kept as plain text.
+func Parse(text string) *Node {
+	text = strings.ReplaceAll(text, "\r\n", "\n")
+	return &Node{Kind: KindDocument, Children: parseBlocks(strings.Split(text, "\n"))}
+}
+
+// parseBlocks splits lines into block nodes.
+func parseBlocks(lines []string) []*Node {
+	var blocks []*Node
+	var paragraph []string
+
+	flush := func() {
+		if len(paragraph) > 0 {
+			blocks = append(blocks, newParagraph(paragraph))
+			paragraph = nil
+		}
+	}
+
+	for i := 0; i < len(lines); {
+		line := lines[i]
+
+		if isBlank(line) {
+			flush()
+			i++
+			continue
+		}
+
+		if fence, info, indent, ok := openingFence(line); ok {
+			flush()
+			block, next := parseCodeBlock(lines, i+1, fence, info, indent)
+			blocks = append(blocks, block)
+			i = next
+			continue
+		}
+
+		// "Title" underlined with === or --- is a heading.
+		if m := setextRe.FindStringSubmatch(line); m != nil && len(paragraph) > 0 {
+			level := 2
+			if m[1][0] == '=' {
+				level = 1
+			}
+			heading := newParagraph(paragraph)
+			heading.Kind, heading.Level = KindHeading, level
+			blocks = append(blocks, heading)
+			paragraph = nil
+			i++
+			continue
+		}
+
+		if thematicBreakRe.MatchString(line) {
+			flush()
+			blocks = append(blocks, &Node{Kind: KindThematicBreak})
+			i++
+			continue
+		}
+
+		if blockQuoteRe.MatchString(line) {
+			flush()
+			quote, next := parseBlockQuote(lines, i)
+			blocks = append(blocks, quote)
+			i = next
+			continue
+		}
+
+		// An ordered list only interrupts a paragraph when it starts at 1,
+		// so wrapped text such as "in\n2024. we" stays a paragraph.
+		if list, next, ok := parseList(lines, i); ok && (len(paragraph) == 0 || !list.Ordered || list.Start == 1) {
+			flush()
+			blocks = append(blocks, list)
+			i = next
+			continue
+		}
+
+		if table, next, ok := parseTable(lines, i); ok {
+			flush()
+			blocks = append(blocks, table)
+			i = next
+			continue
+		}
+
+		if m := headingRe.FindStringSubmatch(line); m != nil {
+			flush()
+			blocks = append(blocks, &Node{
+				Kind:     KindHeading,
+				Level:    len(m[1]),
+				Children: parseInlines(m[2]),
+			})
+			i++
+			continue
+		}
+
+		if m := subtextRe.FindStringSubmatch(line); m != nil {
+			flush()
+			blocks = append(blocks, &Node{Kind: KindSubtext, Children: parseInlines(m[1])})
+			i++
+			continue
+		}
+
+		paragraph = append(paragraph, line)
+		i++
+	}
+	flush()
+
+	return blocks
+}
+
+func newParagraph(lines []string) *Node {
+	for i, line := range lines {
+		lines[i] = strings.TrimSpace(line)
+	}
+
+	return &Node{
+		Kind:     KindParagraph,
+		Children: parseInlines(strings.Join(lines, "\n")),
+	}
+}
+
+// openingFence reports whether line opens a fenced code block and returns the
+// fence run, the info string and the indentation of the fence.
+func openingFence(line string) (string, string, int, bool) {
+	indent := leadingSpaces(line)
+	if indent > 3 {
+		return "", "", 0, false
+	}
+
+	rest := line[indent:]
+	if len(rest) < 3 || (rest[0] != '`' && rest[0] != '~') {
+		return "", "", 0, false
+	}
+
+	n := 0
+	for n < len(rest) && rest[n] == rest[0] {
+		n++
+	}
+	if n < 3 {
+		return "", "", 0, false
+	}
+
+	info := strings.TrimSpace(rest[n:])
+	if rest[0] == '`' && strings.Contains(info, "`") {
+		return "", "", 0, false
+	}
+	if i := strings.IndexAny(info, " \t"); i >= 0 {
+		info = info[:i]
+	}
+
+	return rest[:n], info, indent, true
+}
+
+// isClosingFence reports whether line closes a code block opened by fence.
+func isClosingFence(line, fence string) bool {
+	indent := leadingSpaces(line)
+	if indent > 3 {
+		return false
+	}
+
+	rest := line[indent:]
+	n := 0
+	for n < len(rest) && rest[n] == fence[0] {
+		n++
+	}
+
+	return n >= len(fence) && isBlank(rest[n:])
+}
+
+// parseCodeBlock collects the content of a fenced code block starting at
+// lines[start]. An unclosed fence runs to the end of the text.
+func parseCodeBlock(lines []string, start int, fence, info string, indent int) (*Node, int) {
+	var content []string
+
+	i := start
+	for ; i < len(lines); i++ {
+		if isClosingFence(lines[i], fence) {
+			i++
+			break
+		}
+
+		content = append(content, stripIndent(lines[i], indent))
+	}
+
+	return &Node{
+		Kind:    KindCodeBlock,
+		Info:    info,
+		Literal: strings.Join(content, "\n"),
+	}, i
+}
+
+// startsBlock reports whether line starts a block that interrupts a
+// paragraph or a table.
+func startsBlock(line string) bool {
+	if _, _, _, ok := openingFence(line); ok {
+		return true
+	}
+	return headingRe.MatchString(line) || subtextRe.MatchString(line) ||
+		thematicBreakRe.MatchString(line) || blockQuoteRe.MatchString(line) ||
+		listItemRe.MatchString(line)
+}
+
+func isBlank(line string) bool {
+	return strings.TrimSpace(line) == ""
+}
+
+// tabStop is the width of a tab in indentation, as in CommonMark.
+const tabStop = 4
+
+// leadingSpaces returns the width of the indentation of line in columns, a
+// tab reaching to the next multiple of tabStop. Tabs are only expanded to
+// measure indentation; the text itself, and code in particular, keeps them.
+func leadingSpaces(line string) int {
+	width := 0
+	for i := 0; i < len(line); i++ {
+		switch line[i] {
+		case ' ':
+			width++
+		case '\t':
+			width += tabStop - width%tabStop
+		default:
+			return width
+		}
+	}
+	return width
+}
+
+// stripIndent removes up to n columns of indentation from line. A tab that
+// is only partly removed leaves the rest of its width as spaces.
+func stripIndent(line string, n int) string {
+	width := 0
+	for i := 0; i < len(line); i++ {
+		if width >= n {
+			return line[i:]
+		}
+		switch line[i] {
+		case ' ':
+			width++
+		case '\t':
+			width += tabStop - width%tabStop
+		default:
+			return line[i:]
+		}
+		if width > n {
+			return strings.Repeat(" ", width-n) + line[i+1:]
+		}
+	}
+	return ""
+}
@@ -0,0 +1,212 @@
+package formatter
+
+import (
+	"html"
+	"testing"
+)
+
+func TestParseCodeBlock(t *testing.T) {
+	tests := []struct {
+		name     string
+		text     string
+		info     string
+		literal  string
+		trailing string
+	}{
+		{
+			name:    "backticks",
+			text:    "```go\nfmt.Println(1)\n```",
+			info:    "go",
+			literal: "fmt.Println(1)",
+		},
+		{
+			name:    "tildes",
+			text:    "~~~\ncode\n~~~",
+			literal: "code",
+		},
+		{
+			name:    "tabs kept",
+			text:    "```go\nfunc f() {\n\treturn\n}\n```",
+			info:    "go",
+			literal: "func f() {\n\treturn\n}",
+		},
+		{
+			name:    "tabs inside lines kept",
+			text:    "```\na\tb\n```",
+			literal: "a\tb",
+		},
+		{
+			name:    "fence indentation stripped",
+			text:    "  ```\n  one\n    two\n three\n  ```",
+			literal: "one\n  two\nthree",
+		},
+		{
+			name:    "partly stripped tab",
+			text:    "  ```\n\tx\n  ```",
+			literal: "  x",
+		},
+		{
+			name:    "info string words",
+			text:    "```python title=\"x\"\npass\n```",
+			info:    "python",
+			literal: "pass",
+		},
+		{
+			name:    "longer closing fence",
+			text:    "```\ncode\n`````",
+			literal: "code",
+		},
+		{
+			name:    "shorter fence is content",
+			text:    "````\n```\n````",
+			literal: "```",
+		},
+		{
+			name:    "unclosed fence",
+			text:    "```\nline one\nline two",
+			literal: "line one\nline two",
+		},
+		{
+			name:     "markup inside is literal",
+			text:     "```\n**not bold** <b>\n```\nafter",
+			literal:  "**not bold** <b>",
+			trailing: "after",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			doc := Parse(tt.text)
+			if len(doc.Children) == 0 || doc.Children[0].Kind != KindCodeBlock {
+				t.Fatalf("Parse(%q) does not start with a code block", tt.text)
+			}
+
+			block := doc.Children[0]
+			if block.Info != tt.info {
+				t.Errorf("info = %q, want %q", block.Info, tt.info)
+			}
+			if block.Literal != tt.literal {
+				t.Errorf("literal = %q, want %q", block.Literal, tt.literal)
+			}
+
+			var trailing string
+			if len(doc.Children) > 1 {
+				trailing = doc.Children[1].PlainText()
+			}
+			if trailing != tt.trailing {
+				t.Errorf("text after the block = %q, want %q", trailing, tt.trailing)
+			}
+		})
+	}
+}
+
+func TestParseCodeBlockInList(t *testing.T) {
+	doc := Parse("- item\n\n  ```\n  \tindented\n  ```\n- next")
+
+	if len(doc.Children) != 1 || doc.Children[0].Kind != KindList {
+		t.Fatalf("want a single list, got %d blocks", len(doc.Children))
+	}
+	list := doc.Children[0]
+	if len(list.Children) != 2 {
+		t.Fatalf("want 2 items, got %d", len(list.Children))
+	}
+
+	item := list.Children[0]
+	if len(item.Children) != 2 || item.Children[1].Kind != KindCodeBlock {
+		t.Fatalf("first item does not hold a paragraph and a code block")
+	}
+	if got := item.Children[1].Literal; got != "\tindented" {
+		t.Errorf("code = %q, want %q", got, "\tindented")
+	}
+}
+
+func TestParseTabIndentation(t *testing.T) {
+	// A tab indents a nested list like four spaces would.
+	doc := Parse("- outer\n\t- inner")
+
+	if len(doc.Children) != 1 || doc.Children[0].Kind != KindList {
+		t.Fatalf("want a single list, got %d blocks", len(doc.Children))
+	}
+	item := doc.Children[0].Children[0]
+	if len(item.Children) != 2 || item.Children[1].Kind != KindList {
+		t.Fatalf("the tab-indented line is not a nested list")
+	}
+	if got := item.Children[1].PlainText(); got != "inner" {
+		t.Errorf("nested item = %q, want %q", got, "inner")
+	}
+}
+
+func TestCodeBlockRoundTrip(t *testing.T) {
+	code := "func main() {\n\tif x < 1 && y > 2 {\n\t\tfmt.Println(`*_~|`)\n\t}\n}"
+	text := "```go\n" + code + "\n```"
+
+	if got := Render(text, PlainRenderer{}); got != code {
+		t.Errorf("plain rendering = %q, want %q", got, code)
+	}
+
+	// Rendered code blocks parse back to the same code, once the entities
+	// Slack needs are decoded.
+	for _, tt := range []struct {
+		name     string
+		renderer Renderer
+	}{
+		{"slack", SlackRenderer{}},
+		{"discord", DiscordRenderer{}},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			rendered := html.UnescapeString(Render(text, tt.renderer))
+			doc := Parse(rendered)
+			if len(doc.Children) != 1 || doc.Children[0].Kind != KindCodeBlock {
+				t.Fatalf("rendering %q does not parse as one code block", rendered)
+			}
+			if got := doc.Children[0].Literal; got != code {
+				t.Errorf("code = %q, want %q", got, code)
+			}
+		})
+	}
+}
+
+func TestLeadingSpaces(t *testing.T) {
+	tests := []struct {
+		line string
+		want int
+	}{
+		{"text", 0},
+		{"  text", 2},
+		{"\ttext", 4},
+		{"  \ttext", 4},
+		{"    \ttext", 8},
+		{" \t \ttext", 8},
+		{"   ", 3},
+	}
+
+	for _, tt := range tests {
+		if got := leadingSpaces(tt.line); got != tt.want {
+			t.Errorf("leadingSpaces(%q) = %d, want %d", tt.line, got, tt.want)
+		}
+	}
+}
+
+func TestStripIndent(t *testing.T) {
+	tests := []struct {
+		line string
+		n    int
+		want string
+	}{
+		{"    text", 2, "  text"},
+		{"  text", 4, "text"},
+		{"\ttext", 4, "text"},
+		{"\ttext", 2, "  text"},
+		{"\t\ttext", 4, "\ttext"},
+		{"  \ttext", 2, "\ttext"},
+		{"  \ttext", 3, " text"},
+		{"text", 2, "text"},
+		{"  ", 4, ""},
+	}
+
+	for _, tt := range tests {
+		if got := stripIndent(tt.line, tt.n); got != tt.want {
+			t.Errorf("stripIndent(%q, %d) = %q, want %q", tt.line, tt.n, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,55 @@
+package formatter
+
+import "strconv"
+
+// PlainRenderer renders documents as plain text for destinations that show
+// no formatting. Markers are dropped, links keep their URL after the label,
+// list items get •, ◦ and ▪ bullets by depth, quotes are prefixed with >
+// and tables are laid out as aligned columns.
+type PlainRenderer struct{}
+
+var plainListStyle = listStyle{
+	bullet: glyphBullet,
+	number: func(n int) string { return strconv.Itoa(n) + "." },
+	indent: "    ",
+}
+
+func (r PlainRenderer) Render(doc *Node) string {
+	return joinBlocks(doc.Children, r.block)
+}
+
+func (r PlainRenderer) block(n *Node) string {
+	switch n.Kind {
+	case KindCodeBlock:
+		return n.Literal
+	case KindTable:
+		return renderTableText(n)
+	case KindList:
+		return renderList(n, 0, plainListStyle, r.block)
+	case KindBlockQuote:
+		return renderQuote(n, "> ", ">", r.block)
+	case KindThematicBreak:
+		return thematicBreak
+	default:
+		return renderChildren(n, r.inline)
+	}
+}
+
+func (r PlainRenderer) inline(n *Node) string {
+	switch n.Kind {
+	case KindText, KindCode:
+		return n.Literal
+	case KindSoftBreak:
+		return "\n"
+	case KindMention, KindEmoji:
+		return n.PlainText()
+	case KindLink:
+		label := renderChildren(n, r.inline)
+		if label == n.URL {
+			return n.URL
+		}
+		return label + " (" + n.URL + ")"
+	default:
+		return renderChildren(n, r.inline)
+	}
+}
@@ -0,0 +1,34 @@
+package formatter
+
+import "strings"
+
+// Renderer turns a parsed document into the markup of a platform.
+type Renderer interface {
+	Render(doc *Node) string
+}
+
+// Render parses text and renders it with r.
+func Render(text string, r Renderer) string {
+	return r.Render(Parse(text))
+}
+
+// inlineRenderer renders a single inline node; renderChildren and joinBlocks
+// use it to walk the tree.
+type inlineRenderer func(n *Node) string
+
+func renderChildren(n *Node, render inlineRenderer) string {
+	var b strings.Builder
+	for _, child := range n.Children {
+		b.WriteString(render(child))
+	}
+	return b.String()
+}
+
+// joinBlocks renders the blocks of a container separated by blank lines.
+func joinBlocks(blocks []*Node, render func(*Node) string) string {
+	parts := make([]string, 0, len(blocks))
+	for _, block := range blocks {
+		parts = append(parts, render(block))
+	}
+	return strings.Join(parts, "\n\n")
+}
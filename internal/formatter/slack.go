@@ -0,0 +1,119 @@
+package formatter
+
+import (
+	"strconv"
+	"strings"
+)
+
+// SlackRenderer renders documents as Slack mrkdwn:
+// - *bold* for bold (single asterisk)
+// - _italic_ for italic
+// - ~strike~ for strikethrough
+// - `code` for inline code
+// - ```code block``` for code blocks
+// - <url|text> for links (or just <url> for plain links)
+// - > for quotes
+// - <@U123>, <!subteam^S123>, <!here>, <!channel> and <!everyone> for
+// mentions, with IDs from Directory
+// - :shortcode: for emoji
+//
+// &, < and > are escaped everywhere, so text cannot contain Slack control
+// sequences such as <!channel>. With SafeMentions, @here, @channel and
+// @everyone are written as plain text that notifies no one.
+//
+// Slack has no headings, lists, rules, spoilers or tables: headings become
+// bold lines, list items get •, ◦ and ▪ bullets by depth, rules are drawn
+// with box-drawing characters, subtext becomes italic, spoilers are shown as
+// plain text and tables are laid out as aligned monospace text in a code
+// block.
+type SlackRenderer struct {
+	Directory    Directory
+	SafeMentions bool
+}
+
+var slackListStyle = listStyle{
+	bullet: glyphBullet,
+	number: func(n int) string { return strconv.Itoa(n) + "." },
+	indent: "    ",
+}
+
+func (r SlackRenderer) Render(doc *Node) string {
+	return joinBlocks(doc.Children, r.block)
+}
+
+func (r SlackRenderer) block(n *Node) string {
+	switch n.Kind {
+	case KindCodeBlock:
+		// Slack has no syntax highlighting, so the language is dropped.
+		return "```\n" + escapeSlack(n.Literal) + "\n```"
+	case KindTable:
+		return "```\n" + escapeSlack(renderTableText(n)) + "\n```"
+	case KindHeading:
+		return "*" + renderChildren(n.WithoutStrong(), r.inline) + "*"
+	case KindList:
+		return renderList(n, 0, slackListStyle, r.block)
+	case KindBlockQuote:
+		return renderQuote(n, "> ", ">", r.block)
+	case KindThematicBreak:
+		return thematicBreak
+	case KindSubtext:
+		return "_" + renderChildren(n, r.inline) + "_"
+	default:
+		return renderChildren(n, r.inline)
+	}
+}
+
+func (r SlackRenderer) inline(n *Node) string {
+	switch n.Kind {
+	case KindText:
+		return escapeSlack(n.Literal)
+	case KindSoftBreak:
+		return "\n"
+	case KindCode:
+		return "`" + escapeSlack(n.Literal) + "`"
+	case KindStrong:
+		return "*" + renderChildren(n, r.inline) + "*"
+	case KindEmphasis:
+		return "_" + renderChildren(n, r.inline) + "_"
+	case KindStrikethrough:
+		return "~" + renderChildren(n, r.inline) + "~"
+	case KindMention:
+		switch n.Info {
+		case MentionHere, MentionChannel, MentionEveryone:
+			if r.SafeMentions {
+				return n.PlainText()
+			}
+			return "<!" + n.Info + ">"
+		}
+		if id, ok := r.Directory.lookup(n); ok {
+			if n.Info == MentionGroup {
+				return "<!subteam^" + id + ">"
+			}
+			return "<@" + id + ">"
+		}
+		return n.PlainText()
+	case KindEmoji:
+		// Slack knows the standard shortcodes and the workspace's own.
+		return ":" + n.Literal + ":"
+	case KindLink:
+		// Slack does not format link labels, so only their text is kept.
+		label := n.PlainText()
+		if label == n.URL {
+			return "<" + escapeSlack(n.URL) + ">"
+		}
+		return "<" + escapeSlackURL(n.URL) + "|" + escapeSlack(label) + ">"
+	default:
+		return renderChildren(n, r.inline)
+	}
+}
+
+// escapeSlack escapes the characters Slack reserves for its control
+// sequences.
+func escapeSlack(text string) string {
+	return htmlEscaper.Replace(text)
+}
+
+// escapeSlackURL escapes a link target, including the | that would end it.
+func escapeSlackURL(url string) string {
+	return strings.ReplaceAll(escapeSlack(url), "|", "%7C")
+}
@@ -0,0 +1,569 @@
+package formatter
+
+import (
+	"fmt"
+	"strings"
+	"unicode/utf16"
+	"unicode/utf8"
+)
+
+// Message length limits of the platforms, in UTF-16 code units as counted by
+// their APIs.
+const (
+	SlackMessageLimit    = 40000
+	TelegramMessageLimit = 4096
+	TelegramCaptionLimit = 1024
+	DiscordMessageLimit  = 2000
+)
+
+// SplitOptions controls how Split chunks a message.
+type SplitOptions struct {
+	// Limit is the maximum length of a rendered part.
+	Limit int
+	// FirstLimit, when set, is the limit of the first part instead, for a
+	// first part that has a different limit, such as a caption.
+	FirstLimit int
+	// Number appends the part number, as in "(1/3)", to every part of a
+	// message that needed splitting.
+	Number bool
+}
+
+// Split renders text with r and splits the result into parts no longer than
+// opts.Limit. Parts end on block boundaries where possible, then on line
+// and word boundaries. Code blocks, lists, quotes and tables that are cut
+// are closed in one part and reopened in the next (table parts repeat the
+// header), and formatting spans are closed and reopened the same way, so
+// every part is valid markup on its own. Only a table row or list item
+// longer than the limit loses its formatting: it is sent as plain text cut
+// at line and word boundaries.
+func Split(text string, r Renderer, opts SplitOptions) []string {
+	var out []string
+	for _, part := range SplitDocument(Parse(text), r, opts) {
+		for _, text := range cutText(r.Render(part), opts.Limit) {
+			if strings.TrimSpace(text) != "" {
+				out = append(out, text)
+			}
+		}
+	}
+	if len(out) == 0 {
+		out = append(out, "")
+	}
+	return out
+}
+
+// SplitDocument is Split before rendering: it returns a document per part,
+// sized for r. Keeping the parts as documents lets a caller render a part
+// again with a different renderer, for instance to fall back to plainer
+// markup after the platform rejected it.
+func SplitDocument(doc *Node, r Renderer, opts SplitOptions) []*Node {
+	s := &splitter{renderer: r, limit: opts.Limit, firstLimit: opts.FirstLimit}
+	parts := s.split(doc)
+	if len(parts) == 0 {
+		return []*Node{doc}
+	}
+	if !opts.Number || len(parts) < 2 {
+		return parts
+	}
+
+	// Reserve room for the widest number, and split again if the number of
+	// parts grows to need another digit.
+	for {
+		total := len(parts)
+		s.suffix = numberNode(total, total)
+		parts = s.split(doc)
+		if len(fmt.Sprint(len(parts))) == len(fmt.Sprint(total)) {
+			break
+		}
+	}
+
+	for i, part := range parts {
+		part.Children = append(part.Children, numberNode(i+1, len(parts)))
+	}
+	return parts
+}
+
+func numberNode(n, total int) *Node {
+	return &Node{Kind: KindParagraph, Children: []*Node{{Kind: KindText, Literal: fmt.Sprintf("(%d/%d)", n, total)}}}
+}
+
+// splitter packs the blocks of a document into parts that fit the limit.
+type splitter struct {
+	renderer   Renderer
+	limit      int
+	firstLimit int
+	// suffix is a block every part must leave room for.
+	suffix *Node
+}
+
+func (s *splitter) split(doc *Node) []*Node {
+	if s.firstLimit == 0 || s.firstLimit == s.limit {
+		return s.splitAll(doc)
+	}
+
+	// Take the first part at its own limit, then split what is left of the
+	// document at the usual one.
+	limit := s.limit
+	s.limit = s.firstLimit
+	parts := s.splitAll(doc)
+	s.limit = limit
+	if len(parts) < 2 {
+		return parts
+	}
+
+	rest := &Node{Kind: KindDocument}
+	for _, part := range parts[1:] {
+		rest.Children = append(rest.Children, part.Children...)
+	}
+	return append(parts[:1], s.splitAll(rest)...)
+}
+
+// splitAll splits doc into parts that all fit the limit.
+func (s *splitter) splitAll(doc *Node) []*Node {
+	var parts []*Node
+	for _, part := range s.pack(doc.Children, func(blocks []*Node) *Node {
+		return &Node{Kind: KindDocument, Children: blocks}
+	}, s.splitBlock) {
+		if s.fits(part) {
+			parts = append(parts, part)
+		} else {
+			parts = append(parts, s.cut(part)...)
+		}
+	}
+	return parts
+}
+
+// cut is the last resort for a part that is still too long because it holds
+// a block that cannot be split any further: the part is turned into plain
+// text and cut into paragraphs that fit. A piece is cut shorter until it
+// fits once escaped by the renderer.
+func (s *splitter) cut(part *Node) []*Node {
+	var parts []*Node
+	text := part.PlainText()
+	for strings.TrimSpace(text) != "" {
+		var p *Node
+		end := len(text)
+		for limit := s.limit; ; limit = limit * 9 / 10 {
+			end = cutPoint(text, limit)
+			p = &Node{Kind: KindDocument, Children: []*Node{textParagraph(strings.TrimRight(text[:end], "\n "))}}
+			if s.fits(p) || limit < 10 {
+				break
+			}
+		}
+		parts = append(parts, p)
+		text = text[end:]
+	}
+	if len(parts) == 0 {
+		return []*Node{part}
+	}
+	return parts
+}
+
+// textParagraph returns a paragraph of plain text, with its line breaks.
+func textParagraph(text string) *Node {
+	paragraph := &Node{Kind: KindParagraph}
+	for i, line := range strings.Split(text, "\n") {
+		if i > 0 {
+			paragraph.Children = append(paragraph.Children, &Node{Kind: KindSoftBreak})
+		}
+		if line != "" {
+			paragraph.Children = append(paragraph.Children, &Node{Kind: KindText, Literal: line})
+		}
+	}
+	return paragraph
+}
+
+// fits reports whether n, a document or a single block, renders within the
+// limit.
+func (s *splitter) fits(n *Node) bool {
+	doc := n
+	if n.Kind != KindDocument {
+		doc = &Node{Kind: KindDocument, Children: []*Node{n}}
+	}
+	if s.suffix != nil {
+		doc = &Node{Kind: KindDocument, Children: append(doc.Children[:len(doc.Children):len(doc.Children)], s.suffix)}
+	}
+	return textLength(s.renderer.Render(doc)) <= s.limit
+}
+
+// pack groups items greedily into nodes built by build that fit the limit.
+// An item too large on its own is broken up with split; one that cannot be
+// broken up further is kept whole, and the part it ends up in is cut as
+// plain text by the splitter.
+func (s *splitter) pack(items []*Node, build func([]*Node) *Node, split func(*Node) []*Node) []*Node {
+	var out, group []*Node
+
+	queue := append([]*Node(nil), items...)
+	for len(queue) > 0 {
+		item := queue[0]
+		queue = queue[1:]
+
+		candidate := append(group[:len(group):len(group)], item)
+		if s.fits(build(candidate)) {
+			group = candidate
+			continue
+		}
+
+		// Break up an item too large for a part of its own before closing
+		// the group, so that its first pieces fill the rest of this part.
+		if len(group) > 0 && !s.fits(build([]*Node{item})) {
+			if parts := split(item); len(parts) > 1 {
+				queue = append(parts, queue...)
+				continue
+			}
+		}
+
+		if len(group) > 0 {
+			out = append(out, build(group))
+			group = nil
+			queue = append([]*Node{item}, queue...)
+			continue
+		}
+
+		if parts := split(item); len(parts) > 1 {
+			queue = append(parts, queue...)
+			continue
+		}
+
+		out = append(out, build([]*Node{item}))
+	}
+
+	if len(group) > 0 {
+		out = append(out, build(group))
+	}
+	return out
+}
+
+// splitBlock breaks a block into smaller blocks of the same kind.
+func (s *splitter) splitBlock(n *Node) []*Node {
+	switch n.Kind {
+	case KindParagraph, KindHeading, KindSubtext:
+		return s.splitTextBlock(n)
+
+	case KindCodeBlock:
+		lines := strings.Split(n.Literal, "\n")
+		if len(lines) < 2 {
+			return nil
+		}
+		items := make([]*Node, len(lines))
+		for i, line := range lines {
+			items[i] = &Node{Kind: KindCodeBlock, Info: n.Info, Literal: line}
+		}
+		return s.pack(items, func(group []*Node) *Node {
+			literals := make([]string, len(group))
+			for i, line := range group {
+				literals[i] = line.Literal
+			}
+			return &Node{Kind: KindCodeBlock, Info: n.Info, Literal: strings.Join(literals, "\n")}
+		}, noSplit)
+
+	case KindTable:
+		if len(n.Children) < 3 {
+			return nil
+		}
+		header := n.Children[0]
+		return s.pack(n.Children[1:], func(rows []*Node) *Node {
+			return &Node{Kind: KindTable, Align: n.Align, Children: append([]*Node{header}, rows...)}
+		}, noSplit)
+
+	case KindList:
+		if len(n.Children) < 2 {
+			return nil
+		}
+		items := make([]*Node, len(n.Children))
+		for i, item := range n.Children {
+			items[i] = &Node{Kind: KindList, Ordered: n.Ordered, Start: n.Start + i, Children: []*Node{item}}
+		}
+		return s.pack(items, func(lists []*Node) *Node {
+			list := &Node{Kind: KindList, Ordered: n.Ordered, Start: lists[0].Start}
+			for _, l := range lists {
+				list.Children = append(list.Children, l.Children...)
+			}
+			return list
+		}, noSplit)
+
+	case KindBlockQuote:
+		items := make([]*Node, len(n.Children))
+		for i, child := range n.Children {
+			items[i] = &Node{Kind: KindBlockQuote, Children: []*Node{child}}
+		}
+		return s.pack(items, func(quotes []*Node) *Node {
+			quote := &Node{Kind: KindBlockQuote}
+			for _, q := range quotes {
+				quote.Children = append(quote.Children, q.Children...)
+			}
+			return quote
+		}, func(quote *Node) []*Node {
+			var parts []*Node
+			for _, part := range s.splitBlock(quote.Children[0]) {
+				parts = append(parts, &Node{Kind: KindBlockQuote, Children: []*Node{part}})
+			}
+			return parts
+		})
+	}
+
+	return nil
+}
+
+// splitTextBlock splits a block of inline content on line breaks, and a
+// single line on spaces.
+func (s *splitter) splitTextBlock(n *Node) []*Node {
+	withChildren := func(children []*Node) *Node {
+		block := *n
+		block.Children = mergeSpans(append([]*Node(nil), children...))
+		return &block
+	}
+	splitLine := func(line *Node) []*Node {
+		return s.pack(splitWords(line.Children), withChildren, splitInline)
+	}
+
+	lines := splitLines(n.Children)
+	if len(lines) < 2 {
+		return splitLine(n)
+	}
+
+	items := make([]*Node, len(lines))
+	for i, line := range lines {
+		items[i] = withChildren(line)
+	}
+	return s.pack(items, func(group []*Node) *Node {
+		var children []*Node
+		for i, line := range group {
+			if i > 0 {
+				children = append(children, &Node{Kind: KindSoftBreak})
+			}
+			children = append(children, line.Children...)
+		}
+		return withChildren(children)
+	}, splitLine)
+}
+
+// splitLines groups inline nodes into lines at soft breaks.
+func splitLines(nodes []*Node) [][]*Node {
+	lines := [][]*Node{nil}
+	for _, n := range nodes {
+		if n.Kind == KindSoftBreak {
+			lines = append(lines, nil)
+			continue
+		}
+		lines[len(lines)-1] = append(lines[len(lines)-1], n)
+	}
+	return lines
+}
+
+// splitWords breaks the text nodes among nodes after each space so the words
+// can be packed into parts. Other nodes stay whole.
+func splitWords(nodes []*Node) []*Node {
+	var words []*Node
+	for _, n := range nodes {
+		if n.Kind != KindText {
+			words = append(words, n)
+			continue
+		}
+		for _, word := range strings.SplitAfter(n.Literal, " ") {
+			if word != "" {
+				words = append(words, &Node{Kind: KindText, Literal: word})
+			}
+		}
+	}
+	return words
+}
+
+// splitInline breaks an inline node that does not fit a part on its own. A
+// formatting span is split into spans of the same kind around its words,
+// with the spaces between them left outside the delimiters; the spans that
+// end up in the same part are joined again by mergeSpans, so a span is only
+// really cut where one part ends and the next begins.
+func splitInline(n *Node) []*Node {
+	switch n.Kind {
+	case KindEmphasis, KindStrong, KindStrikethrough, KindSpoiler:
+		words := splitWords(n.Children)
+		if len(words) == 1 {
+			words = splitInline(n.Children[0])
+		}
+		var parts []*Node
+		for _, word := range words {
+			parts = append(parts, wrapSpan(n.Kind, word)...)
+		}
+		return parts
+	case KindCode:
+		words := strings.SplitAfter(n.Literal, " ")
+		if len(words) == 1 {
+			words = halves(n.Literal)
+		}
+		var parts []*Node
+		for _, word := range words {
+			parts = append(parts, wrapSpan(KindCode, &Node{Kind: KindText, Literal: word})...)
+		}
+		return parts
+	case KindText:
+		var parts []*Node
+		for _, half := range halves(n.Literal) {
+			parts = append(parts, &Node{Kind: KindText, Literal: half})
+		}
+		return parts
+	case KindLink:
+		// A link too long for a part can only be shown as text.
+		text := n.PlainText()
+		if text != n.URL {
+			text += " " + n.URL
+		}
+		return splitInline(&Node{Kind: KindText, Literal: text})
+	}
+	return nil
+}
+
+// wrapSpan puts a piece of a span back into a span of kind, leaving the
+// spaces around a text piece outside it.
+func wrapSpan(kind NodeKind, n *Node) []*Node {
+	leading, literal, trailing := "", "", ""
+	if n.Kind == KindText {
+		literal = strings.TrimLeft(n.Literal, " ")
+		leading = n.Literal[:len(n.Literal)-len(literal)]
+		trimmed := strings.TrimRight(literal, " ")
+		trailing = literal[len(trimmed):]
+		literal = trimmed
+		if literal == "" {
+			return []*Node{n}
+		}
+	}
+
+	span := &Node{Kind: kind}
+	switch {
+	case kind == KindCode:
+		span.Literal = literal
+	case n.Kind == KindText:
+		span.Children = []*Node{{Kind: KindText, Literal: literal}}
+	default:
+		span.Children = []*Node{n}
+	}
+
+	var out []*Node
+	if leading != "" {
+		out = append(out, &Node{Kind: KindText, Literal: leading})
+	}
+	out = append(out, span)
+	if trailing != "" {
+		out = append(out, &Node{Kind: KindText, Literal: trailing})
+	}
+	return out
+}
+
+// mergeSpans joins adjacent text nodes, and spans of the same kind that are
+// next to each other or separated only by spaces, undoing splitInline within
+// a part.
+func mergeSpans(nodes []*Node) []*Node {
+	nodes = mergeText(nodes)
+	merged := nodes[:0]
+	for i := 0; i < len(nodes); i++ {
+		n := nodes[i]
+		if len(merged) > 0 {
+			last := merged[len(merged)-1]
+			if joined := joinSpans(last, nil, n); joined != nil {
+				merged[len(merged)-1] = joined
+				continue
+			}
+			if i+1 < len(nodes) && isSpace(n) {
+				if joined := joinSpans(last, n, nodes[i+1]); joined != nil {
+					merged[len(merged)-1] = joined
+					i++
+					continue
+				}
+			}
+		}
+		merged = append(merged, n)
+	}
+	return merged
+}
+
+// joinSpans returns a and b, with the space between them if any, as a
+// single span, or nil when they are not spans of the same kind.
+func joinSpans(a, space, b *Node) *Node {
+	if a.Kind != b.Kind {
+		return nil
+	}
+	switch a.Kind {
+	case KindCode:
+		literal := a.Literal
+		if space != nil {
+			literal += space.Literal
+		}
+		return &Node{Kind: KindCode, Literal: literal + b.Literal}
+	case KindEmphasis, KindStrong, KindStrikethrough, KindSpoiler:
+		children := append([]*Node(nil), a.Children...)
+		if space != nil {
+			children = append(children, space)
+		}
+		return &Node{Kind: a.Kind, Children: mergeSpans(append(children, b.Children...))}
+	}
+	return nil
+}
+
+func isSpace(n *Node) bool {
+	return n.Kind == KindText && strings.Trim(n.Literal, " ") == ""
+}
+
+// halves cuts a single word in two, so that repeated splitting brings it
+// under any limit.
+func halves(word string) []string {
+	runes := []rune(word)
+	if len(runes) < 2 {
+		return nil
+	}
+	return []string{string(runes[:len(runes)/2]), string(runes[len(runes)/2:])}
+}
+
+func noSplit(*Node) []*Node {
+	return nil
+}
+
+// cutText cuts text into pieces no longer than limit, each at the last line
+// break or space before the limit, or at the limit itself.
+func cutText(text string, limit int) []string {
+	var parts []string
+	for textLength(text) > limit {
+		end := cutPoint(text, limit)
+		parts = append(parts, strings.TrimRight(text[:end], "\n "))
+		text = text[end:]
+	}
+	return append(parts, text)
+}
+
+// cutPoint returns the byte offset to cut text at so that the first piece
+// is no longer than limit: after the last line break or space within the
+// limit, or at the limit itself.
+func cutPoint(text string, limit int) int {
+	if textLength(text) <= limit {
+		return len(text)
+	}
+	end := prefixWithin(text, limit)
+	if i := strings.LastIndexAny(text[:end], "\n "); i > 0 {
+		end = i + 1
+	}
+	return end
+}
+
+// prefixWithin returns the byte length of the longest prefix of text whose
+// length is at most limit.
+func prefixWithin(text string, limit int) int {
+	n := 0
+	for i, r := range text {
+		n += utf16.RuneLen(r)
+		if n > limit {
+			if i == 0 {
+				// Always take at least one character.
+				return utf8.RuneLen(r)
+			}
+			return i
+		}
+	}
+	return len(text)
+}
+
+// textLength returns the length of text in UTF-16 code units.
+func textLength(text string) int {
+	n := 0
+	for _, r := range text {
+		n += utf16.RuneLen(r)
+	}
+	return n
+}
@@ -0,0 +1,186 @@
+package formatter
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+)
+
+var splitRenderers = []struct {
+	name     string
+	renderer Renderer
+}{
+	{"slack", SlackRenderer{}},
+	{"telegram", TelegramRenderer{}},
+	{"telegram-html", TelegramHTMLRenderer{}},
+	{"entities", EntityRenderer{}},
+	{"discord", DiscordRenderer{}},
+	{"plain", PlainRenderer{}},
+}
+
+func TestSplitDocumentRoundTrip(t *testing.T) {
+	tests := []struct {
+		name  string
+		text  string
+		limit int
+	}{
+		{"paragraph", strings.Repeat("word ", 100), 60},
+		{"lines", strings.Repeat("a line of text\n", 30), 50},
+		{"bold span", "Intro **" + strings.Repeat("bold text ", 40) + "end** outro", 80},
+		{"nested spans", "**bold " + strings.Repeat("_italic words_ and ", 20) + "done**", 90},
+		{"code span", "`" + strings.Repeat("x ", 80) + "y`", 70},
+		{"code block", "```go\n" + strings.Repeat("fmt.Println(\"hello\")\n", 20) + "```", 120},
+		{"list", strings.Repeat("- list item text\n", 25), 70},
+		{"quote", strings.Repeat("> quoted line\n", 25), 60},
+		{"blocks", "# Title\n\nFirst paragraph.\n\n" + strings.Repeat("Another paragraph here.\n\n", 10), 70},
+		{"long word", strings.Repeat("x", 300), 50},
+	}
+
+	for _, tt := range tests {
+		for _, r := range splitRenderers {
+			t.Run(tt.name+"/"+r.name, func(t *testing.T) {
+				doc := Parse(tt.text)
+				parts := SplitDocument(doc, r.renderer, SplitOptions{Limit: tt.limit})
+
+				var words []string
+				for i, part := range parts {
+					if n := textLength(r.renderer.Render(part)); n > tt.limit {
+						t.Errorf("part %d is %d long, over the limit of %d", i+1, n, tt.limit)
+					}
+					words = append(words, strings.Fields(part.PlainText())...)
+				}
+
+				// Splitting only moves breaks around: the text of the parts
+				// is the text of the document.
+				want := strings.Join(strings.Fields(doc.PlainText()), "")
+				if got := strings.Join(words, ""); got != want {
+					t.Errorf("parts hold %q, want %q", got, want)
+				}
+			})
+		}
+	}
+}
+
+func TestSplitSpansReopen(t *testing.T) {
+	text := "Intro **" + strings.Repeat("bold text ", 40) + "end** and ~~" + strings.Repeat("struck ", 30) + "out~~"
+
+	for _, tt := range []struct {
+		name     string
+		renderer Renderer
+	}{
+		{"slack", SlackRenderer{}},
+		{"discord", DiscordRenderer{}},
+		{"telegram", TelegramRenderer{}},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			parts := Split(text, tt.renderer, SplitOptions{Limit: 100})
+			if len(parts) < 2 {
+				t.Fatalf("got %d parts, want several", len(parts))
+			}
+
+			for i, part := range parts {
+				// A part parses back into the same spans: no delimiter is
+				// left over as text, which would show as raw markup.
+				plain := Parse(part).PlainText()
+				if strings.ContainsAny(plain, "*~") {
+					t.Errorf("part %d %q shows markup as text: %q", i+1, part, plain)
+				}
+			}
+		})
+	}
+}
+
+func TestSplitFillsParts(t *testing.T) {
+	text := "Intro " + "**" + strings.Repeat("bold text ", 30) + "end**"
+	parts := Split(text, DiscordRenderer{}, SplitOptions{Limit: 100})
+
+	if len(parts) < 2 {
+		t.Fatalf("got %d parts, want several", len(parts))
+	}
+	if n := textLength(parts[0]); n < 90 {
+		t.Errorf("first part %q is %d long, want it filled close to the limit", parts[0], n)
+	}
+	if !strings.HasPrefix(parts[0], "Intro **bold") {
+		t.Errorf("first part %q does not start with the prefix and the span", parts[0])
+	}
+}
+
+func TestSplitNumberParts(t *testing.T) {
+	text := strings.Repeat("Some words to split. ", 40)
+	parts := Split(text, PlainRenderer{}, SplitOptions{Limit: 100, Number: true})
+
+	if len(parts) < 2 {
+		t.Fatalf("got %d parts, want several", len(parts))
+	}
+	for i, part := range parts {
+		if n := textLength(part); n > 100 {
+			t.Errorf("part %d is %d long, over the limit", i+1, n)
+		}
+		suffix := fmt.Sprintf("(%d/%d)", i+1, len(parts))
+		if !strings.HasSuffix(part, suffix) {
+			t.Errorf("part %d %q does not end with %q", i+1, part, suffix)
+		}
+	}
+}
+
+func TestSplitDocumentFirstLimit(t *testing.T) {
+	doc := Parse(strings.Repeat("A sentence of text. ", 60))
+	parts := SplitDocument(doc, PlainRenderer{}, SplitOptions{Limit: 400, FirstLimit: 100})
+
+	if len(parts) < 3 {
+		t.Fatalf("got %d parts, want at least 3", len(parts))
+	}
+	for i, part := range parts {
+		limit := 400
+		if i == 0 {
+			limit = 100
+		}
+		if n := textLength(PlainRenderer{}.Render(part)); n > limit {
+			t.Errorf("part %d is %d long, over its limit of %d", i+1, n, limit)
+		}
+	}
+	if n := textLength(PlainRenderer{}.Render(parts[1])); n <= 100 {
+		t.Errorf("second part is %d long, want it split at the full limit", n)
+	}
+}
+
+func TestSplitUnsplittableBlock(t *testing.T) {
+	// A table with a single row cannot be split on its structure, so it is
+	// cut as plain text.
+	text := "| a | b |\n|---|---|\n| " + strings.Repeat("cell.text ", 40) + "| x |"
+
+	for _, r := range splitRenderers {
+		t.Run(r.name, func(t *testing.T) {
+			parts := SplitDocument(Parse(text), r.renderer, SplitOptions{Limit: 100})
+			if len(parts) < 2 {
+				t.Fatalf("got %d parts, want several", len(parts))
+			}
+			for i, part := range parts {
+				if n := textLength(r.renderer.Render(part)); n > 100 {
+					t.Errorf("part %d is %d long, over the limit", i+1, n)
+				}
+			}
+		})
+	}
+}
+
+func TestCutText(t *testing.T) {
+	tests := []struct {
+		text  string
+		limit int
+		want  []string
+	}{
+		{"short", 10, []string{"short"}},
+		{"one two three", 8, []string{"one two", "three"}},
+		{"line one\nline two", 12, []string{"line one", "line two"}},
+		{"abcdefghij", 4, []string{"abcd", "efgh", "ij"}},
+		{"ab😀cd", 3, []string{"ab", "😀c", "d"}},
+	}
+
+	for _, tt := range tests {
+		got := cutText(tt.text, tt.limit)
+		if strings.Join(got, "|") != strings.Join(tt.want, "|") {
+			t.Errorf("cutText(%q, %d) = %q, want %q", tt.text, tt.limit, got, tt.want)
+		}
+	}
+}
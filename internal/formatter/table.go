@@ -0,0 +1,150 @@
+package formatter
+
+import (
+	"regexp"
+	"strings"
+)
+
+var tableDelimiterRe = regexp.MustCompile(`^ {0,3}\|?[ \t]*:?-+:?[ \t]*(\|[ \t]*:?-+:?[ \t]*)*\|?[ \t]*$`)
+
+// parseTable parses a GitHub-style table whose header is lines[start]. It
+// returns false when the lines do not form a table.
+func parseTable(lines []string, start int) (*Node, int, bool) {
+	if start+1 >= len(lines) || !strings.Contains(lines[start], "|") || !tableDelimiterRe.MatchString(lines[start+1]) {
+		return nil, 0, false
+	}
+
+	header := splitTableRow(lines[start])
+	delimiters := splitTableRow(lines[start+1])
+	if len(header) != len(delimiters) {
+		return nil, 0, false
+	}
+
+	align := make([]Alignment, len(delimiters))
+	for i, d := range delimiters {
+		left, right := strings.HasPrefix(d, ":"), strings.HasSuffix(d, ":")
+		switch {
+		case left && right:
+			align[i] = AlignCenter
+		case right:
+			align[i] = AlignRight
+		case left:
+			align[i] = AlignLeft
+		}
+	}
+
+	table := &Node{Kind: KindTable, Align: align}
+	table.Children = append(table.Children, newTableRow(header, len(align)))
+
+	i := start + 2
+	for ; i < len(lines); i++ {
+		line := lines[i]
+		// Unlike GitHub, a line without a pipe ends the table, so text
+		// written right after a table is not swallowed as a row.
+		if isBlank(line) || startsBlock(line) || !strings.Contains(line, "|") {
+			break
+		}
+		table.Children = append(table.Children, newTableRow(splitTableRow(line), len(align)))
+	}
+
+	return table, i, true
+}
+
+// splitTableRow splits a row into cells on unescaped pipes.
+func splitTableRow(line string) []string {
+	line = strings.TrimSpace(line)
+	line = strings.TrimPrefix(line, "|")
+	if strings.HasSuffix(line, "|") && !strings.HasSuffix(line, `\|`) {
+		line = line[:len(line)-1]
+	}
+
+	var cells []string
+	cellStart := 0
+	for i := 0; i < len(line); i++ {
+		switch line[i] {
+		case '\\':
+			i++
+		case '|':
+			cells = append(cells, strings.TrimSpace(line[cellStart:i]))
+			cellStart = i + 1
+		}
+	}
+	return append(cells, strings.TrimSpace(line[cellStart:]))
+}
+
+// newTableRow builds a row with exactly columns cells, padding or cutting the
+// parsed cells as GitHub does.
+func newTableRow(cells []string, columns int) *Node {
+	row := &Node{Kind: KindTableRow}
+	for i := 0; i < columns; i++ {
+		cell := &Node{Kind: KindTableCell}
+		if i < len(cells) {
+			cell.Children = parseInlines(cells[i])
+		}
+		row.Children = append(row.Children, cell)
+	}
+	return row
+}
+
+// renderTableText lays a table out as aligned monospace text, for platforms
+// without native tables. Widths are measured in terminal cells, so Cyrillic
+// and wide CJK or emoji characters line up.
+func renderTableText(table *Node) string {
+	rows := make([][]string, len(table.Children))
+	widths := make([]int, len(table.Align))
+
+	for r, row := range table.Children {
+		for c, cell := range row.Children {
+			text := strings.ReplaceAll(cell.PlainText(), "\n", " ")
+			rows[r] = append(rows[r], text)
+			widths[c] = max(widths[c], displayWidth(text))
+		}
+	}
+
+	lines := make([]string, 0, len(rows)+1)
+	for r, row := range rows {
+		var line strings.Builder
+		for c, text := range row {
+			if c > 0 {
+				line.WriteString(" | ")
+			}
+			line.WriteString(pad(text, widths[c], table.Align[c], c == len(row)-1))
+		}
+		lines = append(lines, strings.TrimRight(line.String(), " "))
+
+		if r == 0 {
+			rule := make([]string, len(widths))
+			for c, w := range widths {
+				rule[c] = strings.Repeat("-", w)
+			}
+			lines = append(lines, strings.Join(rule, "-+-"))
+		}
+	}
+
+	return strings.Join(lines, "\n")
+}
+
+// pad aligns text in a column of width cells. The last column is not padded
+// on the right, so lines carry no trailing spaces.
+func pad(text string, width int, align Alignment, last bool) string {
+	gap := width - displayWidth(text)
+	if gap <= 0 {
+		return text
+	}
+
+	switch align {
+	case AlignRight:
+		return strings.Repeat(" ", gap) + text
+	case AlignCenter:
+		left := gap / 2
+		if last {
+			return strings.Repeat(" ", left) + text
+		}
+		return strings.Repeat(" ", left) + text + strings.Repeat(" ", gap-left)
+	default:
+		if last {
+			return text
+		}
+		return text + strings.Repeat(" ", gap)
+	}
+}
@@ -0,0 +1,71 @@
+package formatter
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestDisplayWidth(t *testing.T) {
+	tests := []struct {
+		text string
+		want int
+	}{
+		{"abc", 3},
+		{"Привіт", 6},
+		{"ї і є ґ", 7},
+		{"и\u0306", 1}, // й written with a combining breve
+		{"日本", 4},
+		{"✅ ❌ ⭐", 8},
+		{"🚀", 2},
+		{"a\u200bb", 2},
+	}
+
+	for _, tt := range tests {
+		if got := displayWidth(tt.text); got != tt.want {
+			t.Errorf("displayWidth(%q) = %d, want %d", tt.text, got, tt.want)
+		}
+	}
+}
+
+func TestRenderTableText(t *testing.T) {
+	tests := []struct {
+		name string
+		text string
+		want []string
+	}{
+		{
+			name: "cyrillic",
+			text: "| Сервіс | Стан |\n|:---|---:|\n| API | працює |\n| База даних | ні |",
+			want: []string{
+				"Сервіс     |   Стан",
+				"-----------+-------",
+				"API        | працює",
+				"База даних |     ні",
+			},
+		},
+		{
+			name: "wide characters",
+			text: "| Назва | Значення |\n|---|:-:|\n| 日本 | ✅ |\n| й | так |",
+			want: []string{
+				"Назва | Значення",
+				"------+---------",
+				"日本  |    ✅",
+				"й     |   так",
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			doc := Parse(tt.text)
+			if len(doc.Children) != 1 || doc.Children[0].Kind != KindTable {
+				t.Fatalf("Parse(%q) is not a table", tt.text)
+			}
+
+			got := renderTableText(doc.Children[0])
+			if want := strings.Join(tt.want, "\n"); got != want {
+				t.Errorf("table =\n%s\nwant\n%s", got, want)
+			}
+		})
+	}
+}
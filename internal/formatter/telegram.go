@@ -0,0 +1,129 @@
+package formatter
+
+import (
+	"strconv"
+	"strings"
+)
+
+// TelegramRenderer renders documents in Telegram's MarkdownV2 format, which
+// requires escaping of _*[]()~`>#+-=|{}.! in text and uses:
+// - *bold* for bold
+// - _italic_ for italic
+// - ~strikethrough~ for strikethrough
+// - ||spoiler|| for spoilers
+// - `code` for inline code
+// - ```lang code block``` for code blocks
+// - [text](url) for links
+// - > for quotes
+// - [name](tg://user?id=123) for user mentions with IDs from Directory
+//
+// Headings become bold lines, list items get •, ◦ and ▪ bullets by depth,
+// rules are drawn with box-drawing characters, subtext becomes italic and
+// tables are laid out as aligned monospace text in a code block.
+//
+// Telegram has no group or mass mentions, so those stay as @name text.
+type TelegramRenderer struct {
+	Directory Directory
+}
+
+var telegramListStyle = listStyle{
+	bullet: glyphBullet,
+	number: func(n int) string { return strconv.Itoa(n) + `\.` },
+	indent: "    ",
+	quote:  ">",
+}
+
+func (r TelegramRenderer) Render(doc *Node) string {
+	return joinBlocks(doc.Children, r.block)
+}
+
+func (r TelegramRenderer) block(n *Node) string {
+	switch n.Kind {
+	case KindCodeBlock:
+		return "```" + n.Info + "\n" + escapeTelegramCode(n.Literal) + "\n```"
+	case KindTable:
+		return "```\n" + escapeTelegramCode(renderTableText(n)) + "\n```"
+	case KindHeading:
+		return "*" + renderChildren(n.WithoutStrong(), r.inline) + "*"
+	case KindList:
+		return renderList(n, 0, telegramListStyle, r.block)
+	case KindBlockQuote:
+		return renderQuote(n, ">", ">", r.block)
+	case KindThematicBreak:
+		return thematicBreak
+	case KindSubtext:
+		return "_" + renderChildren(n, r.inline) + "_"
+	default:
+		return renderChildren(n, r.inline)
+	}
+}
+
+func (r TelegramRenderer) inline(n *Node) string {
+	switch n.Kind {
+	case KindText:
+		return escapeTelegram(n.Literal)
+	case KindSoftBreak:
+		return "\n"
+	case KindCode:
+		return "`" + escapeTelegramCode(n.Literal) + "`"
+	case KindStrong:
+		return "*" + renderChildren(n, r.inline) + "*"
+	case KindEmphasis:
+		return "_" + renderChildren(n, r.inline) + "_"
+	case KindStrikethrough:
+		return "~" + renderChildren(n, r.inline) + "~"
+	case KindSpoiler:
+		return "||" + renderChildren(n, r.inline) + "||"
+	case KindMention:
+		if id, ok := r.Directory.lookup(n); ok && n.Info == MentionUser {
+			return "[" + escapeTelegram(n.Literal) + "](" + escapeTelegramURL(telegramUserURL(id)) + ")"
+		}
+		return escapeTelegram(n.PlainText())
+	case KindEmoji:
+		return escapeTelegram(n.PlainText())
+	case KindLink:
+		if n.PlainText() == n.URL {
+			// Bare URLs are linked by Telegram itself.
+			return escapeTelegram(n.URL)
+		}
+		return "[" + renderChildren(n, r.inline) + "](" + escapeTelegramURL(n.URL) + ")"
+	default:
+		return renderChildren(n, r.inline)
+	}
+}
+
+// telegramUserURL links to a user by ID.
+func telegramUserURL(id string) string {
+	return "tg://user?id=" + id
+}
+
+// telegramSpecialChars must be escaped everywhere in MarkdownV2 text.
+const telegramSpecialChars = "_*[]()~`>#+-=|{}.!\\"
+
+// escapeTelegram escapes text outside of code and link URLs.
+func escapeTelegram(text string) string {
+	return escapeChars(text, telegramSpecialChars)
+}
+
+// escapeTelegramCode escapes the content of code spans and blocks, where
+// only ` and \ are special.
+func escapeTelegramCode(text string) string {
+	return escapeChars(text, "`\\")
+}
+
+// escapeTelegramURL escapes the URL part of an inline link, where only ) and
+// \ are special.
+func escapeTelegramURL(url string) string {
+	return escapeChars(url, ")\\")
+}
+
+func escapeChars(text, special string) string {
+	var b strings.Builder
+	for _, r := range text {
+		if strings.ContainsRune(special, r) {
+			b.WriteByte('\\')
+		}
+		b.WriteRune(r)
+	}
+	return b.String()
+}
@@ -0,0 +1,98 @@
+package formatter
+
+import (
+	"html"
+	"strconv"
+	"strings"
+)
+
+// TelegramHTMLRenderer renders documents in Telegram's HTML parse mode,
+// which only needs &, < and > escaped and so cannot be broken by a stray
+// character the way MarkdownV2 can:
+// - <b>, <i>, <s> and <tg-spoiler> for bold, italic, strikethrough and
+// spoilers
+// - <code> for inline code, <pre><code class="language-x"> for code blocks
+// - <a href="url"> for links
+// - <blockquote> for quotes
+// - <a href="tg://user?id=123"> for user mentions with IDs from Directory
+//
+// Headings become bold lines, subtext becomes italic, list items get •, ◦
+// and ▪ bullets by depth and tables are laid out as aligned monospace text.
+type TelegramHTMLRenderer struct {
+	Directory Directory
+}
+
+var telegramHTMLListStyle = listStyle{
+	bullet: glyphBullet,
+	number: func(n int) string { return strconv.Itoa(n) + "." },
+	indent: "    ",
+}
+
+func (r TelegramHTMLRenderer) Render(doc *Node) string {
+	return joinBlocks(doc.Children, r.block)
+}
+
+func (r TelegramHTMLRenderer) block(n *Node) string {
+	switch n.Kind {
+	case KindCodeBlock:
+		if n.Info == "" {
+			return "<pre>" + escapeHTML(n.Literal) + "</pre>"
+		}
+		return `<pre><code class="language-` + html.EscapeString(n.Info) + `">` + escapeHTML(n.Literal) + "</code></pre>"
+	case KindTable:
+		return "<pre>" + escapeHTML(renderTableText(n)) + "</pre>"
+	case KindHeading:
+		return "<b>" + renderChildren(n.WithoutStrong(), r.inline) + "</b>"
+	case KindSubtext:
+		return "<i>" + renderChildren(n, r.inline) + "</i>"
+	case KindList:
+		return renderList(n, 0, telegramHTMLListStyle, r.block)
+	case KindBlockQuote:
+		return "<blockquote>" + renderQuote(n, "", "", r.block) + "</blockquote>"
+	case KindThematicBreak:
+		return thematicBreak
+	default:
+		return renderChildren(n, r.inline)
+	}
+}
+
+func (r TelegramHTMLRenderer) inline(n *Node) string {
+	switch n.Kind {
+	case KindText:
+		return escapeHTML(n.Literal)
+	case KindSoftBreak:
+		return "\n"
+	case KindCode:
+		return "<code>" + escapeHTML(n.Literal) + "</code>"
+	case KindStrong:
+		return "<b>" + renderChildren(n, r.inline) + "</b>"
+	case KindEmphasis:
+		return "<i>" + renderChildren(n, r.inline) + "</i>"
+	case KindStrikethrough:
+		return "<s>" + renderChildren(n, r.inline) + "</s>"
+	case KindSpoiler:
+		return "<tg-spoiler>" + renderChildren(n, r.inline) + "</tg-spoiler>"
+	case KindMention:
+		if id, ok := r.Directory.lookup(n); ok && n.Info == MentionUser {
+			return `<a href="` + html.EscapeString(telegramUserURL(id)) + `">` + escapeHTML(n.Literal) + "</a>"
+		}
+		return escapeHTML(n.PlainText())
+	case KindEmoji:
+		return escapeHTML(n.PlainText())
+	case KindLink:
+		if n.PlainText() == n.URL {
+			return escapeHTML(n.URL)
+		}
+		return `<a href="` + html.EscapeString(n.URL) + `">` + renderChildren(n, r.inline) + "</a>"
+	default:
+		return renderChildren(n, r.inline)
+	}
+}
+
+// escapeHTML escapes the three characters Telegram requires in HTML text.
+// Quotes are left alone; they only need escaping in attributes.
+func escapeHTML(text string) string {
+	return htmlEscaper.Replace(text)
+}
+
+var htmlEscaper = strings.NewReplacer("&", "&amp;", "<", "&lt;", ">", "&gt;")
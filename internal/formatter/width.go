@@ -0,0 +1,92 @@
+package formatter
+
+import "unicode"
+
+// displayWidth returns the number of monospace cells text occupies: combining
+// marks and zero-width characters take none, East Asian wide characters and
+// emoji take two and everything else, Cyrillic included, takes one.
+func displayWidth(text string) int {
+	width := 0
+	for _, r := range text {
+		width += runeWidth(r)
+	}
+	return width
+}
+
+func runeWidth(r rune) int {
+	switch {
+	case r == 0x200B || r == 0x200C || r == 0x200D || r == 0xFEFF || (r >= 0xFE00 && r <= 0xFE0F):
+		return 0
+	case unicode.Is(unicode.Mn, r) || unicode.Is(unicode.Me, r) || unicode.IsControl(r):
+		return 0
+	case isWide(r):
+		return 2
+	default:
+		return 1
+	}
+}
+
+// wideRanges lists the East Asian wide and fullwidth blocks, the emoji
+// planes and the emoji of the older symbol blocks that are shown as emoji by
+// default.
+var wideRanges = [][2]rune{
+	{0x1100, 0x115F},   // Hangul Jamo
+	{0x231A, 0x231B},   // ⌚ ⌛
+	{0x23E9, 0x23EC},   // ⏩ ⏪ ⏫ ⏬
+	{0x23F0, 0x23F0},   // ⏰
+	{0x23F3, 0x23F3},   // ⏳
+	{0x25FD, 0x25FE},   // ◽ ◾
+	{0x2614, 0x2615},   // ☔ ☕
+	{0x2648, 0x2653},   // Zodiac signs
+	{0x267F, 0x267F},   // ♿
+	{0x2693, 0x2693},   // ⚓
+	{0x26A1, 0x26A1},   // ⚡
+	{0x26AA, 0x26AB},   // ⚪ ⚫
+	{0x26BD, 0x26BE},   // ⚽ ⚾
+	{0x26C4, 0x26C5},   // ⛄ ⛅
+	{0x26CE, 0x26CE},   // ⛎
+	{0x26D4, 0x26D4},   // ⛔
+	{0x26EA, 0x26EA},   // ⛪
+	{0x26F2, 0x26F3},   // ⛲ ⛳
+	{0x26F5, 0x26F5},   // ⛵
+	{0x26FA, 0x26FA},   // ⛺
+	{0x26FD, 0x26FD},   // ⛽
+	{0x2705, 0x2705},   // ✅
+	{0x270A, 0x270B},   // ✊ ✋
+	{0x2728, 0x2728},   // ✨
+	{0x274C, 0x274C},   // ❌
+	{0x274E, 0x274E},   // ❎
+	{0x2753, 0x2755},   // ❓ ❔ ❕
+	{0x2757, 0x2757},   // ❗
+	{0x2795, 0x2797},   // ➕ ➖ ➗
+	{0x27B0, 0x27B0},   // ➰
+	{0x27BF, 0x27BF},   // ➿
+	{0x2B1B, 0x2B1C},   // ⬛ ⬜
+	{0x2B50, 0x2B50},   // ⭐
+	{0x2B55, 0x2B55},   // ⭕
+	{0x2E80, 0x303E},   // CJK radicals, punctuation
+	{0x3041, 0x33FF},   // Hiragana, Katakana, CJK symbols
+	{0x3400, 0x4DBF},   // CJK extension A
+	{0x4E00, 0x9FFF},   // CJK unified ideographs
+	{0xA000, 0xA4CF},   // Yi
+	{0xAC00, 0xD7A3},   // Hangul syllables
+	{0xF900, 0xFAFF},   // CJK compatibility ideographs
+	{0xFE30, 0xFE4F},   // CJK compatibility forms
+	{0xFF00, 0xFF60},   // Fullwidth forms
+	{0xFFE0, 0xFFE6},   // Fullwidth signs
+	{0x1F1E6, 0x1F1FF}, // Regional indicators (flags)
+	{0x1F300, 0x1F64F}, // Misc symbols and pictographs, emoticons
+	{0x1F680, 0x1F6FF}, // Transport and map symbols
+	{0x1F900, 0x1F9FF}, // Supplemental symbols and pictographs
+	{0x1FA70, 0x1FAFF}, // Symbols and pictographs extended-A
+	{0x20000, 0x3FFFD}, // CJK extensions B and later
+}
+
+func isWide(r rune) bool {
+	for _, rg := range wideRanges {
+		if r >= rg[0] && r <= rg[1] {
+			return true
+		}
+	}
+	return false
+}
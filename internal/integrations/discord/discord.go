@@ -1,6 +1,7 @@
 package discord
 
 import (
+	"CLIMultiChat/internal/formatter"
 	messengers "CLIMultiChat/internal/integrations"
 	"fmt"
 
@@ -10,40 +11,202 @@ import (
 type Client struct {
 	session        *discordgo.Session
 	defaultChannel string
+	renderer       formatter.DiscordRenderer
 }
 
-func NewClient(token, defaultChannel string) (messengers.Messenger, error) {
+// NewClient creates a Discord client. directory resolves @user: and @group:
+// mentions to Discord user and role IDs.
+func NewClient(token, defaultChannel string, directory formatter.Directory) (messengers.Messenger, error) {
 	if token == "" {
-		return nil, fmt.Errorf("discord token is required")
+		return nil, messengers.NewError(messengers.ErrorKindConfig, fmt.Errorf("discord token is required"))
 	}
 
 	session, err := discordgo.New("Bot " + token)
 	if err != nil {
-		return nil, fmt.Errorf("failed to create Discord session: %w", err)
+		return nil, messengers.NewError(classifyError(err), fmt.Errorf("failed to create Discord session: %w", err))
 	}
 
 	return &Client{
 		session:        session,
 		defaultChannel: defaultChannel,
+		renderer:       formatter.DiscordRenderer{Directory: directory},
 	}, nil
 }
 
-func (c *Client) SendMessage(channel, message string) error {
-	if channel == "" {
-		if c.defaultChannel == "" {
-			return fmt.Errorf("channel is required")
+// SendMessage sends message to channel. A message over the Discord limit is
+// sent in parts, each replying to the one before it; attachments go with the
+// first part. With an embed option the embed is sent in place of message.
+func (c *Client) SendMessage(channel, message string, opts ...messengers.SendOption) (*messengers.Receipt, error) {
+	channel, err := c.resolveChannel(channel)
+	if err != nil {
+		return nil, err
+	}
+
+	o := messengers.ApplySendOptions(opts)
+	if o.Embed != nil {
+		return c.sendEmbed(channel, o.Embed, c.messageRenderer(o), o)
+	}
+
+	parts := c.split(message, o)
+
+	replyTo := o.ReplyTo
+	var receipt *messengers.Receipt
+	for i, part := range parts {
+		send := &discordgo.MessageSend{Content: part, AllowedMentions: allowedMentions(o)}
+		if replyTo != "" {
+			send.Reference = &discordgo.MessageReference{MessageID: replyTo, ChannelID: channel}
+		}
+
+		if i == 0 && len(o.Attachments) > 0 {
+			files, closeFiles, err := openFiles(o.Attachments)
+			if err != nil {
+				return nil, err
+			}
+			defer closeFiles()
+			send.Files = files
+		}
+
+		msg, err := c.session.ChannelMessageSendComplex(channel, send)
+		if err != nil {
+			return nil, wrapError("send message to", err)
+		}
+
+		if receipt == nil {
+			receipt = c.receipt(msg)
+		} else {
+			receipt.Parts = append(receipt.Parts, msg.ID)
+		}
+		replyTo = msg.ID
+	}
+
+	return receipt, nil
+}
+
+// messageRenderer is the renderer for a message sent with o.
+func (c *Client) messageRenderer(o messengers.SendOptions) formatter.DiscordRenderer {
+	r := c.renderer
+	r.SafeMentions = o.SafeMentions
+	return r
+}
+
+// split renders message and splits it into the parts to send.
+func (c *Client) split(message string, o messengers.SendOptions) []string {
+	return formatter.Split(message, c.messageRenderer(o), formatter.SplitOptions{
+		Limit:  formatter.DiscordMessageLimit,
+		Number: o.NumberParts,
+	})
+}
+
+// Preview renders message the way SendMessage would send it, without a token
+// or a connection. A message with an embed option previews as the embed.
+func Preview(message string, directory formatter.Directory, opts ...messengers.SendOption) (*messengers.Preview, error) {
+	c := &Client{renderer: formatter.DiscordRenderer{Directory: directory}}
+	o := messengers.ApplySendOptions(opts)
+	preview := &messengers.Preview{Platform: c.GetName()}
+
+	if o.Embed != nil {
+		embed := BuildEmbed(o.Embed, c.messageRenderer(o))
+		if err := ValidateEmbed(embed); err != nil {
+			return nil, messengers.NewError(messengers.ErrorKindInput, err)
 		}
-		channel = c.defaultChannel
+		preview.ParseMode = "embed"
+		preview.Parts = append(preview.Parts, messengers.PreviewPart{Fields: requestFields(o, embed)})
+		return preview, nil
+	}
+
+	preview.ParseMode = "markdown"
+	for _, part := range c.split(message, o) {
+		preview.Parts = append(preview.Parts, messengers.PreviewPart{Text: part, Fields: requestFields(o, nil)})
+	}
+	return preview, nil
+}
+
+// requestFields are the fields of a message request besides its content.
+func requestFields(o messengers.SendOptions, embed *discordgo.MessageEmbed) map[string]any {
+	fields := map[string]any{}
+	if embed != nil {
+		fields["embeds"] = []*discordgo.MessageEmbed{embed}
+	}
+	if mentions := allowedMentions(o); mentions != nil {
+		fields["allowed_mentions"] = mentions
+	}
+	if len(fields) == 0 {
+		return nil
+	}
+	return fields
+}
+
+// allowedMentions limits the mentions Discord acts on to users and roles
+// when mass mentions are to be suppressed; otherwise Discord's defaults
+// apply.
+func allowedMentions(o messengers.SendOptions) *discordgo.MessageAllowedMentions {
+	if !o.SafeMentions {
+		return nil
+	}
+	return &discordgo.MessageAllowedMentions{
+		Parse: []discordgo.AllowedMentionType{discordgo.AllowedMentionTypeUsers, discordgo.AllowedMentionTypeRoles},
+	}
+}
+
+func (c *Client) EditMessage(channel, messageID, message string) (*messengers.Receipt, error) {
+	channel, err := c.resolveChannel(channel)
+	if err != nil {
+		return nil, err
+	}
+
+	parts := formatter.Split(message, c.renderer, formatter.SplitOptions{Limit: formatter.DiscordMessageLimit})
+	if len(parts) > 1 {
+		return nil, messengers.NewError(messengers.ErrorKindInput, fmt.Errorf("message is too long to fit in one Discord message"))
+	}
+
+	msg, err := c.session.ChannelMessageEdit(channel, messageID, parts[0])
+	if err != nil {
+		return nil, wrapError("edit message in", err)
 	}
 
-	_, err := c.session.ChannelMessageSend(channel, message)
+	return c.receipt(msg), nil
+}
+
+func (c *Client) DeleteMessage(channel, messageID string) error {
+	channel, err := c.resolveChannel(channel)
 	if err != nil {
-		return fmt.Errorf("failed to send message to Discord: %w", err)
+		return err
+	}
+
+	if err := c.session.ChannelMessageDelete(channel, messageID); err != nil {
+		return wrapError("delete message in", err)
 	}
 
 	return nil
 }
 
+func (c *Client) resolveChannel(channel string) (string, error) {
+	if channel != "" {
+		return channel, nil
+	}
+	if c.defaultChannel == "" {
+		return "", messengers.NewError(messengers.ErrorKindInput, fmt.Errorf("channel is required"))
+	}
+	return c.defaultChannel, nil
+}
+
+func (c *Client) receipt(msg *discordgo.Message) *messengers.Receipt {
+	receipt := &messengers.Receipt{
+		Platform:  c.GetName(),
+		Channel:   msg.ChannelID,
+		MessageID: msg.ID,
+		Timestamp: msg.Timestamp,
+	}
+
+	// Message links need the guild; direct messages use "@me" instead, but
+	// the REST response does not tell the two apart when guild_id is absent.
+	if msg.GuildID != "" {
+		receipt.Permalink = fmt.Sprintf("https://discord.com/channels/%s/%s/%s", msg.GuildID, msg.ChannelID, msg.ID)
+	}
+
+	return receipt
+}
+
 func (c *Client) GetName() string {
 	return "Discord"
 }
@@ -0,0 +1,117 @@
+package discord
+
+import (
+	"CLIMultiChat/internal/formatter"
+	messengers "CLIMultiChat/internal/integrations"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/bwmarrin/discordgo"
+)
+
+// Embed limits, in characters.
+const (
+	MaxEmbedTotal       = 6000
+	MaxEmbedTitle       = 256
+	MaxEmbedDescription = 4096
+	MaxEmbedFields      = 25
+	MaxEmbedFieldName   = 256
+	MaxEmbedFieldValue  = 1024
+	MaxEmbedFooter      = 2048
+)
+
+// BuildEmbed converts an embed spec to a Discord embed, rendering the
+// description and field values as Discord Markdown with r.
+func BuildEmbed(e *messengers.Embed, r formatter.DiscordRenderer) *discordgo.MessageEmbed {
+	embed := &discordgo.MessageEmbed{
+		Title:       e.Title,
+		URL:         e.URL,
+		Description: formatter.Render(e.Description, r),
+		Color:       e.Color,
+	}
+
+	for _, f := range e.Fields {
+		embed.Fields = append(embed.Fields, &discordgo.MessageEmbedField{
+			Name:   f.Name,
+			Value:  formatter.Render(f.Value, r),
+			Inline: f.Inline,
+		})
+	}
+	if e.Footer != "" {
+		embed.Footer = &discordgo.MessageEmbedFooter{Text: e.Footer}
+	}
+	if !e.Timestamp.IsZero() {
+		embed.Timestamp = e.Timestamp.Format(time.RFC3339)
+	}
+
+	return embed
+}
+
+// ValidateEmbed checks a rendered embed against the Discord limits and
+// reports every limit it exceeds.
+func ValidateEmbed(embed *discordgo.MessageEmbed) error {
+	var errs []error
+	check := func(what, text string, limit int) int {
+		n := utf8.RuneCountInString(text)
+		if n > limit {
+			errs = append(errs, fmt.Errorf("embed %s is %d characters long (limit %d)", what, n, limit))
+		}
+		return n
+	}
+
+	total := check("title", embed.Title, MaxEmbedTitle)
+	total += check("description", embed.Description, MaxEmbedDescription)
+	if len(embed.Fields) > MaxEmbedFields {
+		errs = append(errs, fmt.Errorf("embed has %d fields (limit %d)", len(embed.Fields), MaxEmbedFields))
+	}
+	for i, f := range embed.Fields {
+		// Discord rejects fields with a blank name or value.
+		if strings.TrimSpace(f.Name) == "" {
+			errs = append(errs, fmt.Errorf("embed field %d has an empty name", i+1))
+		}
+		if strings.TrimSpace(f.Value) == "" {
+			errs = append(errs, fmt.Errorf("embed field %d has an empty value", i+1))
+		}
+		total += check(fmt.Sprintf("field %d name", i+1), f.Name, MaxEmbedFieldName)
+		total += check(fmt.Sprintf("field %d value", i+1), f.Value, MaxEmbedFieldValue)
+	}
+	if embed.Footer != nil {
+		total += check("footer", embed.Footer.Text, MaxEmbedFooter)
+	}
+	if total > MaxEmbedTotal {
+		errs = append(errs, fmt.Errorf("embed is %d characters long in total (limit %d)", total, MaxEmbedTotal))
+	}
+
+	return errors.Join(errs...)
+}
+
+// sendEmbed sends the embed spec as a single message.
+func (c *Client) sendEmbed(channel string, spec *messengers.Embed, r formatter.DiscordRenderer, o messengers.SendOptions) (*messengers.Receipt, error) {
+	embed := BuildEmbed(spec, r)
+	if err := ValidateEmbed(embed); err != nil {
+		return nil, messengers.NewError(messengers.ErrorKindInput, err)
+	}
+
+	send := &discordgo.MessageSend{Embeds: []*discordgo.MessageEmbed{embed}, AllowedMentions: allowedMentions(o)}
+	if o.ReplyTo != "" {
+		send.Reference = &discordgo.MessageReference{MessageID: o.ReplyTo, ChannelID: channel}
+	}
+	if len(o.Attachments) > 0 {
+		files, closeFiles, err := openFiles(o.Attachments)
+		if err != nil {
+			return nil, err
+		}
+		defer closeFiles()
+		send.Files = files
+	}
+
+	msg, err := c.session.ChannelMessageSendComplex(channel, send)
+	if err != nil {
+		return nil, wrapError("send message to", err)
+	}
+
+	return c.receipt(msg), nil
+}
@@ -0,0 +1,43 @@
+package discord
+
+import (
+	messengers "CLIMultiChat/internal/integrations"
+	"errors"
+	"fmt"
+	"net/http"
+
+	"github.com/bwmarrin/discordgo"
+)
+
+// classifyError maps a Discord REST error to an ErrorKind.
+func classifyError(err error) messengers.ErrorKind {
+	if errors.Is(err, discordgo.ErrUnauthorized) {
+		return messengers.ErrorKindAuth
+	}
+
+	var rateErr *discordgo.RateLimitError
+	if errors.As(err, &rateErr) {
+		return messengers.ErrorKindRateLimit
+	}
+
+	var restErr *discordgo.RESTError
+	if errors.As(err, &restErr) && restErr.Response != nil {
+		switch restErr.Response.StatusCode {
+		case http.StatusUnauthorized, http.StatusForbidden:
+			return messengers.ErrorKindAuth
+		case http.StatusNotFound:
+			return messengers.ErrorKindNotFound
+		case http.StatusTooManyRequests:
+			return messengers.ErrorKindRateLimit
+		default:
+			return messengers.ErrorKindAPI
+		}
+	}
+
+	return messengers.KindOf(err)
+}
+
+// wrapError adds context to a Discord REST error and classifies it.
+func wrapError(action string, err error) error {
+	return messengers.NewError(classifyError(err), fmt.Errorf("failed to %s Discord: %w", action, err))
+}
@@ -0,0 +1,41 @@
+package discord
+
+import (
+	messengers "CLIMultiChat/internal/integrations"
+	"fmt"
+	"mime"
+	"os"
+	"path/filepath"
+
+	"github.com/bwmarrin/discordgo"
+)
+
+// openFiles opens the attachments for a multipart upload. The returned
+// function closes every opened file.
+func openFiles(paths []string) ([]*discordgo.File, func(), error) {
+	var files []*discordgo.File
+	var opened []*os.File
+
+	closeAll := func() {
+		for _, f := range opened {
+			f.Close()
+		}
+	}
+
+	for _, path := range paths {
+		f, err := os.Open(path)
+		if err != nil {
+			closeAll()
+			return nil, nil, messengers.NewError(messengers.ErrorKindInput, fmt.Errorf("failed to open attachment: %w", err))
+		}
+		opened = append(opened, f)
+
+		files = append(files, &discordgo.File{
+			Name:        filepath.Base(path),
+			ContentType: mime.TypeByExtension(filepath.Ext(path)),
+			Reader:      f,
+		})
+	}
+
+	return files, closeAll, nil
+}
@@ -0,0 +1,68 @@
+package messengers
+
+import (
+	"fmt"
+	"strings"
+	"time"
+)
+
+// Embed is a rich announcement card: Discord sends it as an embed, other
+// platforms send the Markdown of Embed.Markdown in its place.
+type Embed struct {
+	Title string
+	URL   string
+	// Description is Markdown, converted like a message body.
+	Description string
+	// Color is the 0xRRGGBB colour of the embed's side bar.
+	Color     int
+	Fields    []EmbedField
+	Footer    string
+	Timestamp time.Time
+}
+
+// EmbedField is a name/value pair of an embed. Inline fields are laid out
+// side by side.
+type EmbedField struct {
+	Name   string
+	Value  string
+	Inline bool
+}
+
+// Markdown returns the embed as a Markdown message for platforms without
+// embeds: the title as a heading, the description, the fields as bold
+// names with their values and the footer as subtext.
+func (e *Embed) Markdown() string {
+	var blocks []string
+
+	if e.Title != "" {
+		title := e.Title
+		if e.URL != "" {
+			title = "[" + title + "](" + e.URL + ")"
+		}
+		blocks = append(blocks, "## "+title)
+	}
+	if e.Description != "" {
+		blocks = append(blocks, e.Description)
+	}
+
+	if len(e.Fields) > 0 {
+		lines := make([]string, 0, len(e.Fields))
+		for _, f := range e.Fields {
+			lines = append(lines, fmt.Sprintf("- **%s:** %s", f.Name, f.Value))
+		}
+		blocks = append(blocks, strings.Join(lines, "\n"))
+	}
+
+	footer := e.Footer
+	if !e.Timestamp.IsZero() {
+		if footer != "" {
+			footer += " • "
+		}
+		footer += e.Timestamp.Format("2006-01-02 15:04 MST")
+	}
+	if footer != "" {
+		blocks = append(blocks, "-# "+footer)
+	}
+
+	return strings.Join(blocks, "\n\n")
+}
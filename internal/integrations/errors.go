@@ -0,0 +1,63 @@
+package messengers
+
+import (
+	"errors"
+	"net"
+)
+
+// ErrorKind classifies a failure so callers can react to it without parsing
+// error messages.
+type ErrorKind string
+
+const (
+	ErrorKindConfig    ErrorKind = "config"
+	ErrorKindInput     ErrorKind = "input"
+	ErrorKindAuth      ErrorKind = "auth"
+	ErrorKindNotFound  ErrorKind = "not_found"
+	ErrorKindRateLimit ErrorKind = "rate_limit"
+	ErrorKindNetwork   ErrorKind = "network"
+	ErrorKindAPI       ErrorKind = "api"
+	ErrorKindUnknown   ErrorKind = "unknown"
+)
+
+// Error attaches an ErrorKind to an underlying error.
+type Error struct {
+	Kind ErrorKind
+	Err  error
+}
+
+func NewError(kind ErrorKind, err error) error {
+	if err == nil {
+		return nil
+	}
+	return &Error{Kind: kind, Err: err}
+}
+
+func (e *Error) Error() string {
+	return e.Err.Error()
+}
+
+func (e *Error) Unwrap() error {
+	return e.Err
+}
+
+// KindOf reports the kind of err. Errors that were not classified by a
+// client are reported as network errors when they come from the network
+// stack and as unknown otherwise.
+func KindOf(err error) ErrorKind {
+	if err == nil {
+		return ""
+	}
+
+	var kindErr *Error
+	if errors.As(err, &kindErr) {
+		return kindErr.Kind
+	}
+
+	var netErr net.Error
+	if errors.As(err, &netErr) {
+		return ErrorKindNetwork
+	}
+
+	return ErrorKindUnknown
+}
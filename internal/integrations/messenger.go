@@ -1,6 +1,103 @@
 package messengers
 
+import "time"
+
+// Receipt describes a message accepted by a messenger. MessageID is the
+// platform identifier needed to edit, delete or reply to the message (the
+// Slack ts, the Telegram message ID, the Discord message ID). Permalink is
+// empty when the platform does not expose one for the destination. A message
+// too long for the platform is sent in parts: the receipt describes the
+// first one and Parts holds the IDs of the rest. ParseMode is the markup the
+// message was sent in on platforms that offer a choice; when its parts were
+// not all sent in the same markup, ParseMode is that of the last part and
+// PartParseModes holds the markup of each part in order. Preview is set
+// instead of the IDs when the message was only rendered, not sent.
+type Receipt struct {
+	Platform  string
+	Channel   string
+	MessageID string
+	Timestamp time.Time
+	Permalink string
+	Parts     []string
+	ParseMode string
+	// PartParseModes is only set when the parts differ in markup.
+	PartParseModes []string
+	Preview        *Preview
+}
+
 type Messenger interface {
-	SendMessage(channel, message string) error
+	SendMessage(channel, message string, opts ...SendOption) (*Receipt, error)
+	EditMessage(channel, messageID, message string) (*Receipt, error)
+	DeleteMessage(channel, messageID string) error
 	GetName() string
 }
+
+// SendOptions holds the optional parameters of SendMessage. Clients ignore
+// options their platform has no equivalent for.
+type SendOptions struct {
+	// ReplyTo is the ID of the message to reply to: the thread_ts on Slack,
+	// reply_to_message_id on Telegram and a message reference on Discord.
+	ReplyTo string
+	// ThreadBroadcast also posts a Slack thread reply to the channel.
+	ThreadBroadcast bool
+	// Attachments are paths of files uploaded with the message; the message
+	// text becomes their caption.
+	Attachments []string
+	// Embed sends the message as a Discord embed. The message passed to
+	// SendMessage is then the fallback for other platforms, usually
+	// Embed.Markdown.
+	Embed *Embed
+	// NumberParts numbers the parts of a message split to fit the platform
+	// limit, as in "(1/3)".
+	NumberParts bool
+	// SafeMentions keeps mass mentions (@here, @channel, @everyone and
+	// their raw platform forms) from notifying anyone.
+	SafeMentions bool
+}
+
+type SendOption func(*SendOptions)
+
+func WithReplyTo(messageID string) SendOption {
+	return func(o *SendOptions) {
+		o.ReplyTo = messageID
+	}
+}
+
+func WithThreadBroadcast() SendOption {
+	return func(o *SendOptions) {
+		o.ThreadBroadcast = true
+	}
+}
+
+func WithAttachments(paths ...string) SendOption {
+	return func(o *SendOptions) {
+		o.Attachments = append(o.Attachments, paths...)
+	}
+}
+
+func WithEmbed(embed *Embed) SendOption {
+	return func(o *SendOptions) {
+		o.Embed = embed
+	}
+}
+
+func WithNumberedParts() SendOption {
+	return func(o *SendOptions) {
+		o.NumberParts = true
+	}
+}
+
+func WithSafeMentions() SendOption {
+	return func(o *SendOptions) {
+		o.SafeMentions = true
+	}
+}
+
+// ApplySendOptions collects opts into a SendOptions value.
+func ApplySendOptions(opts []SendOption) SendOptions {
+	var o SendOptions
+	for _, opt := range opts {
+		opt(&o)
+	}
+	return o
+}
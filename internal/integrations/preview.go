@@ -0,0 +1,18 @@
+package messengers
+
+// Preview is a message rendered the way a client would send it, without
+// sending it. Each part is one request to the platform, in the order they
+// would be made; ParseMode is the markup they are in.
+type Preview struct {
+	Platform  string
+	ParseMode string
+	Parts     []PreviewPart
+}
+
+// PreviewPart is one message of a preview: its text and the other fields of
+// the request (Slack blocks, Telegram entities, a Discord embed), keyed by
+// their names in the platform API.
+type PreviewPart struct {
+	Text   string
+	Fields map[string]any
+}
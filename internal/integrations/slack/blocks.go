@@ -0,0 +1,131 @@
+package slack
+
+import (
+	"CLIMultiChat/internal/formatter"
+	"strings"
+
+	"github.com/slack-go/slack"
+)
+
+// Message formats of the Slack client.
+const (
+	FormatMrkdwn = "mrkdwn"
+	FormatBlocks = "blocks"
+)
+
+// Block Kit limits.
+const (
+	// MaxBlocks is the most blocks Slack accepts in one message.
+	MaxBlocks = 50
+	// sectionTextLimit is the longest text of a section block.
+	sectionTextLimit = 3000
+	// headerTextLimit is the longest text of a header block.
+	headerTextLimit = 150
+	// contextTextLimit is the longest text of a context block element.
+	contextTextLimit = 3000
+)
+
+// BlockMessage is one message of a Block Kit rendering: its blocks and the
+// plain-text fallback Slack shows in notifications.
+type BlockMessage struct {
+	Blocks []slack.Block
+	Text   string
+}
+
+// RenderBlocks renders Markdown as Block Kit. Headings become header blocks,
+// paragraphs, lists and quotes section blocks, rules dividers, subtext
+// context blocks, and code blocks and tables rich_text_preformatted blocks.
+// Text is rendered as mrkdwn with r. A document with more blocks than a
+// message can hold is spread over several messages.
+func RenderBlocks(message string, r formatter.SlackRenderer) []BlockMessage {
+	var messages []BlockMessage
+	var blocks []slack.Block
+	var nodes []*formatter.Node
+
+	flush := func() {
+		if len(blocks) > 0 {
+			messages = append(messages, BlockMessage{Blocks: blocks, Text: fallbackText(nodes)})
+			blocks, nodes = nil, nil
+		}
+	}
+
+	for _, n := range formatter.Parse(message).Children {
+		nodeBlocks := renderBlock(n, r)
+		if len(blocks)+len(nodeBlocks) > MaxBlocks {
+			flush()
+		}
+
+		for len(nodeBlocks) > MaxBlocks {
+			messages = append(messages, BlockMessage{Blocks: nodeBlocks[:MaxBlocks], Text: fallbackText([]*formatter.Node{n})})
+			nodeBlocks = nodeBlocks[MaxBlocks:]
+		}
+		blocks = append(blocks, nodeBlocks...)
+		nodes = append(nodes, n)
+	}
+	flush()
+
+	return messages
+}
+
+func renderBlock(n *formatter.Node, r formatter.SlackRenderer) []slack.Block {
+	switch n.Kind {
+	case formatter.KindHeading:
+		if text := n.PlainText(); len([]rune(text)) <= headerTextLimit {
+			return []slack.Block{slack.NewHeaderBlock(slack.NewTextBlockObject(slack.PlainTextType, text, true, false))}
+		}
+		return sections(n, r)
+	case formatter.KindThematicBreak:
+		return []slack.Block{slack.NewDividerBlock()}
+	case formatter.KindSubtext:
+		var blocks []slack.Block
+		paragraph := &formatter.Node{Kind: formatter.KindParagraph, Children: n.Children}
+		for _, part := range formatter.SplitDocument(document(paragraph), r, formatter.SplitOptions{Limit: contextTextLimit}) {
+			text := slack.NewTextBlockObject(slack.MarkdownType, r.Render(part), false, false)
+			blocks = append(blocks, slack.NewContextBlock("", text))
+		}
+		return blocks
+	case formatter.KindCodeBlock:
+		return []slack.Block{preformatted(n.Literal)}
+	case formatter.KindTable:
+		return []slack.Block{preformatted(formatter.PlainRenderer{}.Render(document(n)))}
+	default:
+		return sections(n, r)
+	}
+}
+
+// sections renders a block as mrkdwn section blocks, split to fit the
+// section text limit.
+func sections(n *formatter.Node, r formatter.SlackRenderer) []slack.Block {
+	var blocks []slack.Block
+	for _, part := range formatter.SplitDocument(document(n), r, formatter.SplitOptions{Limit: sectionTextLimit}) {
+		text := slack.NewTextBlockObject(slack.MarkdownType, r.Render(part), false, false)
+		blocks = append(blocks, slack.NewSectionBlock(text, nil, nil))
+	}
+	return blocks
+}
+
+func preformatted(code string) slack.Block {
+	pre := &slack.RichTextPreformatted{
+		RichTextSection: slack.RichTextSection{
+			Type:     slack.RTEPreformatted,
+			Elements: []slack.RichTextSectionElement{slack.NewRichTextSectionTextElement(code, nil)},
+		},
+	}
+	return slack.NewRichTextBlock("", pre)
+}
+
+// fallbackText is the plain text of nodes, cut to the message limit and
+// escaped so that it holds no control sequences.
+func fallbackText(nodes []*formatter.Node) string {
+	text := formatter.PlainRenderer{}.Render(&formatter.Node{Kind: formatter.KindDocument, Children: nodes})
+	if runes := []rune(text); len(runes) > formatter.SlackMessageLimit {
+		text = string(runes[:formatter.SlackMessageLimit-1]) + "…"
+	}
+	return fallbackEscaper.Replace(text)
+}
+
+var fallbackEscaper = strings.NewReplacer("&", "&amp;", "<", "&lt;", ">", "&gt;")
+
+func document(n *formatter.Node) *formatter.Node {
+	return &formatter.Node{Kind: formatter.KindDocument, Children: []*formatter.Node{n}}
+}
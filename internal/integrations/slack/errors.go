@@ -0,0 +1,38 @@
+package slack
+
+import (
+	messengers "CLIMultiChat/internal/integrations"
+	"errors"
+	"fmt"
+
+	"github.com/slack-go/slack"
+)
+
+// classifyError maps a Slack API error to an ErrorKind.
+func classifyError(err error) messengers.ErrorKind {
+	var rateErr *slack.RateLimitedError
+	if errors.As(err, &rateErr) {
+		return messengers.ErrorKindRateLimit
+	}
+
+	var apiErr slack.SlackErrorResponse
+	if errors.As(err, &apiErr) {
+		switch apiErr.Err {
+		case "invalid_auth", "not_authed", "account_inactive", "token_revoked", "token_expired", "missing_scope", "not_in_channel":
+			return messengers.ErrorKindAuth
+		case "channel_not_found", "message_not_found", "thread_not_found", "user_not_found":
+			return messengers.ErrorKindNotFound
+		case "ratelimited":
+			return messengers.ErrorKindRateLimit
+		default:
+			return messengers.ErrorKindAPI
+		}
+	}
+
+	return messengers.KindOf(err)
+}
+
+// wrapError adds context to a Slack API error and classifies it.
+func wrapError(action string, err error) error {
+	return messengers.NewError(classifyError(err), fmt.Errorf("failed to %s Slack: %w", action, err))
+}
@@ -0,0 +1,85 @@
+package slack
+
+import (
+	messengers "CLIMultiChat/internal/integrations"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/slack-go/slack"
+)
+
+// sendFiles uploads the attachments to the channel, using text as the
+// initial comment of the first file.
+func (c *Client) sendFiles(channel, text string, o messengers.SendOptions) (*messengers.Receipt, error) {
+	var first *slack.FileSummary
+
+	for i, path := range o.Attachments {
+		comment := ""
+		if i == 0 {
+			comment = text
+		}
+
+		summary, err := c.uploadFile(channel, path, comment, o.ReplyTo)
+		if err != nil {
+			return nil, err
+		}
+		if first == nil {
+			first = summary
+		}
+	}
+
+	return c.fileReceipt(channel, first), nil
+}
+
+func (c *Client) uploadFile(channel, path, comment, threadTS string) (*slack.FileSummary, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, messengers.NewError(messengers.ErrorKindInput, fmt.Errorf("failed to open attachment: %w", err))
+	}
+	defer f.Close()
+
+	info, err := f.Stat()
+	if err != nil {
+		return nil, messengers.NewError(messengers.ErrorKindInput, fmt.Errorf("failed to open attachment: %w", err))
+	}
+
+	summary, err := c.api.UploadFileV2(slack.UploadFileV2Parameters{
+		Reader:          f,
+		FileSize:        int(info.Size()),
+		Filename:        filepath.Base(path),
+		Channel:         channel,
+		InitialComment:  comment,
+		ThreadTimestamp: threadTS,
+	})
+	if err != nil {
+		return nil, wrapError("upload file to", err)
+	}
+
+	return summary, nil
+}
+
+// fileReceipt builds the receipt of an upload. files.completeUploadExternal
+// does not return the message ts, so it is looked up from the file shares on
+// a best-effort basis.
+func (c *Client) fileReceipt(channel string, summary *slack.FileSummary) *messengers.Receipt {
+	receipt := &messengers.Receipt{
+		Platform: c.GetName(),
+		Channel:  channel,
+	}
+
+	file, _, _, err := c.api.GetFileInfo(summary.ID, 0, 0)
+	if err != nil {
+		return receipt
+	}
+
+	for _, shares := range []map[string][]slack.ShareFileInfo{file.Shares.Public, file.Shares.Private} {
+		for sharedChannel, infos := range shares {
+			if len(infos) > 0 {
+				return c.receipt(sharedChannel, infos[0].Ts)
+			}
+		}
+	}
+
+	return receipt
+}
@@ -4,6 +4,10 @@ import (
 	"CLIMultiChat/internal/formatter"
 	messengers "CLIMultiChat/internal/integrations"
 	"fmt"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
 
 	"github.com/slack-go/slack"
 )
@@ -11,11 +15,26 @@ import (
 type Client struct {
 	api            *slack.Client
 	defaultChannel string
+	format         string
+	renderer       formatter.SlackRenderer
+
+	// workspaceURL is the address of the workspace permalinks are built on,
+	// looked up once with auth.test.
+	workspaceOnce sync.Once
+	workspaceURL  string
 }
 
-func NewClient(token, defaultChannel string) (messengers.Messenger, error) {
+// NewClient creates a Slack client. format selects how messages are posted:
+// FormatMrkdwn (the default, also used for "") or FormatBlocks. directory
+// resolves @user: and @group: mentions to Slack member and user group IDs.
+func NewClient(token, defaultChannel, format string, directory formatter.Directory) (messengers.Messenger, error) {
 	if token == "" {
-		return nil, fmt.Errorf("slack token is required")
+		return nil, messengers.NewError(messengers.ErrorKindConfig, fmt.Errorf("slack token is required"))
+	}
+
+	format, err := formatFor(format)
+	if err != nil {
+		return nil, err
 	}
 
 	api := slack.New(token)
@@ -23,33 +42,252 @@ func NewClient(token, defaultChannel string) (messengers.Messenger, error) {
 	return &Client{
 		api:            api,
 		defaultChannel: defaultChannel,
+		format:         format,
+		renderer:       formatter.SlackRenderer{Directory: directory},
 	}, nil
 }
 
-func (c *Client) SendMessage(channel, message string) error {
-	if channel == "" {
-		if c.defaultChannel == "" {
-			return fmt.Errorf("channel is required")
+func formatFor(format string) (string, error) {
+	switch format {
+	case "":
+		return FormatMrkdwn, nil
+	case FormatMrkdwn, FormatBlocks:
+		return format, nil
+	default:
+		return "", messengers.NewError(messengers.ErrorKindConfig, fmt.Errorf("unknown Slack format %q (expected mrkdwn or blocks)", format))
+	}
+}
+
+// Preview renders message the way SendMessage would post it in the given
+// format, without a token or a connection.
+func Preview(message, format string, directory formatter.Directory, opts ...messengers.SendOption) (*messengers.Preview, error) {
+	format, err := formatFor(format)
+	if err != nil {
+		return nil, err
+	}
+
+	o := messengers.ApplySendOptions(opts)
+	if len(o.Attachments) > 0 {
+		// File comments cannot hold blocks.
+		format = FormatMrkdwn
+	}
+
+	c := &Client{format: format, renderer: formatter.SlackRenderer{Directory: directory}}
+	preview := &messengers.Preview{Platform: c.GetName(), ParseMode: format}
+	for _, part := range c.render(message, o) {
+		p := messengers.PreviewPart{Text: part.text}
+		if len(part.blocks) > 0 {
+			p.Fields = map[string]any{"blocks": part.blocks}
 		}
-		channel = c.defaultChannel
+		preview.Parts = append(preview.Parts, p)
 	}
+	return preview, nil
+}
 
-	// Convert markdown to Slack format
-	formattedMessage := formatter.ToSlackMarkdown(message)
+// SendMessage posts message to channel as mrkdwn text or, in the blocks
+// format, as Block Kit. A message over the Slack limits is sent in parts:
+// the first goes where the message would have gone and the rest follow in
+// its thread (or in the thread replied to). Attachments always go with
+// mrkdwn text, as file comments cannot hold blocks; when the ts of the
+// upload cannot be found, the parts after it are not sent, since they could
+// not be threaded.
+func (c *Client) SendMessage(channel, message string, opts ...messengers.SendOption) (*messengers.Receipt, error) {
+	channel, err := c.resolveChannel(channel)
+	if err != nil {
+		return nil, err
+	}
 
-	_, _, err := c.api.PostMessage(
-		channel,
-		slack.MsgOptionText(formattedMessage, false),
-		slack.MsgOptionAsUser(true),
-	)
+	o := messengers.ApplySendOptions(opts)
+	parts := c.render(message, o)
+	if len(parts) == 0 {
+		return nil, messengers.NewError(messengers.ErrorKindInput, fmt.Errorf("message is empty"))
+	}
 
+	var receipt *messengers.Receipt
+	if len(o.Attachments) > 0 {
+		receipt, err = c.sendFiles(channel, parts[0].text, o)
+	} else {
+		receipt, err = c.postMessage(channel, parts[0], o.ReplyTo, o.ThreadBroadcast)
+	}
 	if err != nil {
-		return fmt.Errorf("failed to send message to Slack: %w", err)
+		return nil, err
+	}
+
+	threadTS := o.ReplyTo
+	if threadTS == "" {
+		threadTS = receipt.MessageID
+	}
+	if threadTS == "" && len(parts) > 1 {
+		// The ts of an upload is looked up on a best-effort basis. Without
+		// it the rest of the message would land in the channel instead of
+		// the thread.
+		return nil, messengers.NewError(messengers.ErrorKindAPI, fmt.Errorf("attachments sent to %s, but Slack did not return their message ts, so the remaining %d parts of the message were not sent", channel, len(parts)-1))
+	}
+	for _, part := range parts[1:] {
+		sent, err := c.postMessage(receipt.Channel, part, threadTS, false)
+		if err != nil {
+			return nil, err
+		}
+		receipt.Parts = append(receipt.Parts, sent.MessageID)
+	}
+
+	return receipt, nil
+}
+
+// content is one message as posted: mrkdwn text, or blocks with text as
+// their notification fallback.
+type content struct {
+	text   string
+	blocks []slack.Block
+}
+
+// render turns message into the messages to post, split to fit the limits.
+func (c *Client) render(message string, o messengers.SendOptions) []content {
+	var parts []content
+
+	r := c.renderer
+	r.SafeMentions = o.SafeMentions
+
+	if c.format == FormatBlocks && len(o.Attachments) == 0 {
+		messages := RenderBlocks(message, r)
+		for i, m := range messages {
+			if o.NumberParts && len(messages) > 1 && len(m.Blocks) < MaxBlocks {
+				number := fmt.Sprintf("(%d/%d)", i+1, len(messages))
+				m.Blocks = append(m.Blocks, slack.NewContextBlock("", slack.NewTextBlockObject(slack.PlainTextType, number, false, false)))
+			}
+			parts = append(parts, content{text: m.Text, blocks: m.Blocks})
+		}
+		return parts
+	}
+
+	for _, text := range formatter.Split(message, r, formatter.SplitOptions{
+		Limit:  formatter.SlackMessageLimit,
+		Number: o.NumberParts,
+	}) {
+		parts = append(parts, content{text: text})
+	}
+	return parts
+}
+
+func (m content) msgOptions() []slack.MsgOption {
+	opts := []slack.MsgOption{slack.MsgOptionText(m.text, false)}
+	if len(m.blocks) > 0 {
+		opts = append(opts, slack.MsgOptionBlocks(m.blocks...))
+	}
+	return opts
+}
+
+func (c *Client) postMessage(channel string, m content, threadTS string, broadcast bool) (*messengers.Receipt, error) {
+	msgOptions := append(m.msgOptions(), slack.MsgOptionAsUser(true))
+	if threadTS != "" {
+		msgOptions = append(msgOptions, slack.MsgOptionTS(threadTS))
+		if broadcast {
+			msgOptions = append(msgOptions, slack.MsgOptionBroadcast())
+		}
+	}
+
+	respChannel, ts, err := c.api.PostMessage(channel, msgOptions...)
+
+	if err != nil {
+		return nil, wrapError("send message to", err)
+	}
+
+	return c.receipt(respChannel, ts), nil
+}
+
+func (c *Client) EditMessage(channel, messageID, message string) (*messengers.Receipt, error) {
+	channel, err := c.resolveChannel(channel)
+	if err != nil {
+		return nil, err
+	}
+
+	parts := c.render(message, messengers.SendOptions{})
+	if len(parts) == 0 {
+		return nil, messengers.NewError(messengers.ErrorKindInput, fmt.Errorf("message is empty"))
+	}
+	if len(parts) > 1 {
+		return nil, messengers.NewError(messengers.ErrorKindInput, fmt.Errorf("message is too long to fit in one Slack message"))
+	}
+
+	respChannel, ts, _, err := c.api.UpdateMessage(channel, messageID, parts[0].msgOptions()...)
+
+	if err != nil {
+		return nil, wrapError("edit message in", err)
+	}
+
+	return c.receipt(respChannel, ts), nil
+}
+
+func (c *Client) DeleteMessage(channel, messageID string) error {
+	channel, err := c.resolveChannel(channel)
+	if err != nil {
+		return err
+	}
+
+	if _, _, err := c.api.DeleteMessage(channel, messageID); err != nil {
+		return wrapError("delete message in", err)
 	}
 
 	return nil
 }
 
+func (c *Client) resolveChannel(channel string) (string, error) {
+	if channel != "" {
+		return channel, nil
+	}
+	if c.defaultChannel == "" {
+		return "", messengers.NewError(messengers.ErrorKindInput, fmt.Errorf("channel is required"))
+	}
+	return c.defaultChannel, nil
+}
+
+// receipt builds the receipt of a posted message. The permalink is built
+// from the workspace address on a best-effort basis: a failure to look it
+// up leaves the permalink empty and does not fail the send.
+func (c *Client) receipt(channel, ts string) *messengers.Receipt {
+	return &messengers.Receipt{
+		Platform:  c.GetName(),
+		Channel:   channel,
+		MessageID: ts,
+		Timestamp: parseTimestamp(ts),
+		Permalink: c.permalink(channel, ts),
+	}
+}
+
+// permalink returns the link of a message, as chat.getPermalink would,
+// without a request per message: "https://team.slack.com/archives/C123/p1700000000123456".
+func (c *Client) permalink(channel, ts string) string {
+	c.workspaceOnce.Do(func() {
+		if auth, err := c.api.AuthTest(); err == nil {
+			c.workspaceURL = strings.TrimSuffix(auth.URL, "/")
+		}
+	})
+	if c.workspaceURL == "" || ts == "" {
+		return ""
+	}
+	return c.workspaceURL + "/archives/" + channel + "/p" + strings.Replace(ts, ".", "", 1)
+}
+
+// parseTimestamp converts a Slack message ts ("1700000000.123456") to a
+// time. The seconds and microseconds are parsed as integers, as a float64
+// cannot hold every microsecond of a current timestamp exactly.
+func parseTimestamp(ts string) time.Time {
+	secondsText, microsText, _ := strings.Cut(ts, ".")
+	seconds, err := strconv.ParseInt(secondsText, 10, 64)
+	if err != nil {
+		return time.Time{}
+	}
+
+	var micros int64
+	if microsText != "" {
+		microsText = (microsText + "000000")[:6]
+		if micros, err = strconv.ParseInt(microsText, 10, 64); err != nil {
+			return time.Time{}
+		}
+	}
+	return time.Unix(seconds, micros*int64(time.Microsecond))
+}
+
 func (c *Client) GetName() string {
 	return "Slack"
 }
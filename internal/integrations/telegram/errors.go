@@ -0,0 +1,34 @@
+package telegram
+
+import (
+	messengers "CLIMultiChat/internal/integrations"
+	"errors"
+	"fmt"
+	"strings"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+)
+
+// classifyError maps a Telegram Bot API error to an ErrorKind.
+func classifyError(err error) messengers.ErrorKind {
+	var apiErr *tgbotapi.Error
+	if errors.As(err, &apiErr) {
+		switch {
+		case apiErr.Code == 401 || apiErr.Code == 403:
+			return messengers.ErrorKindAuth
+		case apiErr.Code == 429:
+			return messengers.ErrorKindRateLimit
+		case strings.Contains(apiErr.Message, "not found"):
+			return messengers.ErrorKindNotFound
+		default:
+			return messengers.ErrorKindAPI
+		}
+	}
+
+	return messengers.KindOf(err)
+}
+
+// wrapError adds context to a Telegram API error and classifies it.
+func wrapError(action string, err error) error {
+	return messengers.NewError(classifyError(err), fmt.Errorf("failed to %s Telegram: %w", action, err))
+}
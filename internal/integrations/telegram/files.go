@@ -0,0 +1,124 @@
+package telegram
+
+import (
+	messengers "CLIMultiChat/internal/integrations"
+	"path/filepath"
+	"strings"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+)
+
+// maxMediaGroup is the largest number of files Telegram accepts in one
+// sendMediaGroup call.
+const maxMediaGroup = 10
+
+var photoExtensions = map[string]bool{
+	".jpg": true, ".jpeg": true, ".png": true, ".webp": true,
+}
+
+// sendFiles sends the attachments with caption as the caption of the first
+// file: a single file goes through sendPhoto or sendDocument, several files
+// through sendMediaGroup, in groups of at most ten (see mediaGroups).
+func (c *Client) sendFiles(chatID int64, caption content, replyTo int, paths []string) (*messengers.Receipt, error) {
+	if len(paths) == 1 {
+		return c.sendFile(chatID, caption, replyTo, paths[0])
+	}
+
+	// A media group cannot mix photos with documents, so photos are only
+	// sent as photos when every attachment is one.
+	asPhotos := true
+	for _, path := range paths {
+		if !isPhoto(path) {
+			asPhotos = false
+			break
+		}
+	}
+
+	var receipt *messengers.Receipt
+	for _, group := range mediaGroups(len(paths)) {
+		start, end := group[0], group[1]
+
+		media := make([]interface{}, 0, end-start)
+		for i, path := range paths[start:end] {
+			var item tgbotapi.BaseInputMedia
+			if asPhotos {
+				item = tgbotapi.NewInputMediaPhoto(tgbotapi.FilePath(path)).BaseInputMedia
+			} else {
+				item = tgbotapi.NewInputMediaDocument(tgbotapi.FilePath(path)).BaseInputMedia
+			}
+			if start == 0 && i == 0 {
+				item.Caption = caption.text
+				item.ParseMode = caption.parseMode
+				item.CaptionEntities = caption.entities
+			}
+
+			if asPhotos {
+				media = append(media, tgbotapi.InputMediaPhoto{BaseInputMedia: item})
+			} else {
+				media = append(media, tgbotapi.InputMediaDocument{BaseInputMedia: item})
+			}
+		}
+
+		config := tgbotapi.NewMediaGroup(chatID, media)
+		config.ReplyToMessageID = replyTo
+
+		sent, err := c.bot.SendMediaGroup(config)
+		if err != nil {
+			return nil, wrapError("send files to", err)
+		}
+		if receipt == nil && len(sent) > 0 {
+			receipt = c.receipt(sent[0])
+		}
+	}
+
+	return receipt, nil
+}
+
+// mediaGroups divides n files, n > 1, into the [start, end) ranges of as
+// few media groups as possible. The groups are of even size, as a media
+// group needs at least two files and eleven files cannot go as ten and one.
+func mediaGroups(n int) [][2]int {
+	count := (n + maxMediaGroup - 1) / maxMediaGroup
+	groups := make([][2]int, 0, count)
+	start := 0
+	for i := 0; i < count; i++ {
+		size := n / count
+		if i < n%count {
+			size++
+		}
+		groups = append(groups, [2]int{start, start + size})
+		start += size
+	}
+	return groups
+}
+
+func (c *Client) sendFile(chatID int64, caption content, replyTo int, path string) (*messengers.Receipt, error) {
+	var msg tgbotapi.Chattable
+
+	if isPhoto(path) {
+		photo := tgbotapi.NewPhoto(chatID, tgbotapi.FilePath(path))
+		photo.Caption = caption.text
+		photo.ParseMode = caption.parseMode
+		photo.CaptionEntities = caption.entities
+		photo.ReplyToMessageID = replyTo
+		msg = photo
+	} else {
+		document := tgbotapi.NewDocument(chatID, tgbotapi.FilePath(path))
+		document.Caption = caption.text
+		document.ParseMode = caption.parseMode
+		document.CaptionEntities = caption.entities
+		document.ReplyToMessageID = replyTo
+		msg = document
+	}
+
+	sent, err := c.bot.Send(msg)
+	if err != nil {
+		return nil, wrapError("send file to", err)
+	}
+
+	return c.receipt(sent), nil
+}
+
+func isPhoto(path string) bool {
+	return photoExtensions[strings.ToLower(filepath.Ext(path))]
+}
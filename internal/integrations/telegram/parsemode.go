@@ -0,0 +1,136 @@
+package telegram
+
+import (
+	"CLIMultiChat/internal/formatter"
+	messengers "CLIMultiChat/internal/integrations"
+	"errors"
+	"fmt"
+	"strings"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+)
+
+// The modes a message can be sent in. MarkdownV2 and HTML are Telegram
+// parse modes; entities sends plain text with a list of MessageEntity
+// values, and plain sends text with no formatting at all.
+const (
+	modeEntities   = "entities"
+	modeMarkdownV2 = tgbotapi.ModeMarkdownV2
+	modeHTML       = tgbotapi.ModeHTML
+	modePlain      = "plain"
+)
+
+// parseModeFor returns the mode for a configured mode name.
+func parseModeFor(name string) (string, error) {
+	switch strings.ToLower(name) {
+	case "", "entities":
+		return modeEntities, nil
+	case "markdownv2":
+		return modeMarkdownV2, nil
+	case "html":
+		return modeHTML, nil
+	case "plain":
+		return modePlain, nil
+	default:
+		return "", fmt.Errorf("unknown parse mode %q (expected entities, markdownv2, html or plain)", name)
+	}
+}
+
+// fallbackMode returns the mode to retry in after Telegram failed to parse
+// a message: MarkdownV2 falls back to HTML, and HTML and entities to plain
+// text.
+func fallbackMode(mode string) (string, bool) {
+	switch mode {
+	case modeMarkdownV2:
+		return modeHTML, true
+	case modeHTML, modeEntities:
+		return modePlain, true
+	default:
+		return "", false
+	}
+}
+
+// Fallback returns the parse mode a message is sent in when Telegram
+// cannot parse it in parseMode, named as in the config, or false if there
+// is none to fall back to.
+func Fallback(parseMode string) (string, bool) {
+	mode, err := parseModeFor(parseMode)
+	if err != nil {
+		return "", false
+	}
+	next, ok := fallbackMode(mode)
+	return modeName(next), ok
+}
+
+func (c *Client) renderer(mode string) formatter.Renderer {
+	switch mode {
+	case modeEntities:
+		return formatter.EntityRenderer{Directory: c.directory}
+	case modeMarkdownV2:
+		return formatter.TelegramRenderer{Directory: c.directory}
+	case modeHTML:
+		return formatter.TelegramHTMLRenderer{Directory: c.directory}
+	default:
+		return formatter.PlainRenderer{}
+	}
+}
+
+// content is a message rendered for the Bot API: text with either a
+// parse_mode or a list of entities.
+type content struct {
+	text      string
+	parseMode string
+	entities  []tgbotapi.MessageEntity
+}
+
+func (c *Client) render(doc *formatter.Node, mode string) content {
+	switch mode {
+	case modeEntities:
+		text, entities := formatter.EntityRenderer{Directory: c.directory}.RenderEntities(doc)
+		rendered := content{text: text}
+		for _, e := range entities {
+			rendered.entities = append(rendered.entities, tgbotapi.MessageEntity{
+				Type:     e.Type,
+				Offset:   e.Offset,
+				Length:   e.Length,
+				URL:      e.URL,
+				Language: e.Language,
+			})
+		}
+		return rendered
+	case modeMarkdownV2, modeHTML:
+		return content{text: c.renderer(mode).Render(doc), parseMode: mode}
+	default:
+		return content{text: c.renderer(mode).Render(doc)}
+	}
+}
+
+// modeName is the name of a mode as it appears in receipts and the config.
+func modeName(mode string) string {
+	return strings.ToLower(mode)
+}
+
+// isParseError reports whether Telegram rejected a message because its
+// markup or entities could not be parsed.
+func isParseError(err error) bool {
+	var apiErr *tgbotapi.Error
+	return errors.As(err, &apiErr) && strings.Contains(apiErr.Message, "can't parse entities")
+}
+
+// withFallback calls send with doc rendered in mode, retrying in the
+// fallback modes while Telegram cannot parse the result. It returns the
+// mode that worked.
+func (c *Client) withFallback(doc *formatter.Node, mode string, send func(content) (*messengers.Receipt, error)) (*messengers.Receipt, string, error) {
+	for {
+		receipt, err := send(c.render(doc, mode))
+		if err == nil || !isParseError(err) {
+			return receipt, mode, err
+		}
+
+		next, ok := fallbackMode(mode)
+		if !ok {
+			return nil, mode, err
+		}
+		mode = next
+	}
+}
@@ -3,6 +3,7 @@ package telegram
 import (
 	"fmt"
 	"strconv"
+	"strings"
 
 	"CLIMultiChat/internal/formatter"
 	messengers "CLIMultiChat/internal/integrations"
@@ -13,61 +14,286 @@ import (
 type Client struct {
 	bot           *tgbotapi.BotAPI
 	defaultChatID int64
+	parseMode     string
+	directory     formatter.Directory
 }
 
-func NewClient(token, defaultChatID string) (messengers.Messenger, error) {
+// NewClient creates a Telegram client. parseMode is how messages are
+// formatted first: "entities" (the default), "markdownv2", "html" or
+// "plain". directory resolves @user: mentions to Telegram user IDs.
+func NewClient(token, defaultChatID, parseMode string, directory formatter.Directory) (messengers.Messenger, error) {
 	if token == "" {
-		return nil, fmt.Errorf("telegram bot token is required")
+		return nil, messengers.NewError(messengers.ErrorKindConfig, fmt.Errorf("telegram bot token is required"))
+	}
+
+	mode, err := parseModeFor(parseMode)
+	if err != nil {
+		return nil, messengers.NewError(messengers.ErrorKindConfig, err)
 	}
 
 	bot, err := tgbotapi.NewBotAPI(token)
 	if err != nil {
-		return nil, fmt.Errorf("failed to create Telegram bot: %w", err)
+		return nil, messengers.NewError(classifyError(err), fmt.Errorf("failed to create Telegram bot: %w", err))
 	}
 
 	var chatID int64
 	if defaultChatID != "" {
 		chatID, err = strconv.ParseInt(defaultChatID, 10, 64)
 		if err != nil {
-			return nil, fmt.Errorf("invalid default chat ID: %w", err)
+			return nil, messengers.NewError(messengers.ErrorKindConfig, fmt.Errorf("invalid default chat ID: %w", err))
 		}
 	}
 
 	return &Client{
 		bot:           bot,
 		defaultChatID: chatID,
+		parseMode:     mode,
+		directory:     directory,
 	}, nil
 }
 
-func (c *Client) SendMessage(chatIDStr, message string) error {
-	var chatID int64
-	var err error
+// SendMessage sends message to the chat. A message over the Telegram limit
+// is sent in parts, each replying to the one before it. When Telegram cannot
+// parse the formatting of a part, it is sent again in a plainer mode (see
+// fallbackMode) and the rest of the message stays in that mode; the receipt
+// reports the mode the message ended up in, and the mode of each part when
+// the fallback happened after the first one was sent.
+func (c *Client) SendMessage(chatIDStr, message string, opts ...messengers.SendOption) (*messengers.Receipt, error) {
+	chatID, err := c.resolveChatID(chatIDStr)
+	if err != nil {
+		return nil, err
+	}
 
-	if chatIDStr == "" {
-		if c.defaultChatID == 0 {
-			return fmt.Errorf("chat ID is required")
+	o := messengers.ApplySendOptions(opts)
+
+	var replyTo int
+	if o.ReplyTo != "" {
+		replyTo, err = parseMessageID(o.ReplyTo)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	parts := c.split(message, o)
+
+	mode := c.parseMode
+	var receipt *messengers.Receipt
+	var modes []string
+	for i := 0; i < len(parts); i++ {
+		text := c.render(parts[i], mode)
+		var sent *messengers.Receipt
+		if i == 0 && len(o.Attachments) > 0 {
+			sent, err = c.sendFiles(chatID, text, replyTo, o.Attachments)
+		} else {
+			sent, err = c.sendText(chatID, text, replyTo)
 		}
-		chatID = c.defaultChatID
-	} else {
-		chatID, err = strconv.ParseInt(chatIDStr, 10, 64)
 		if err != nil {
-			return fmt.Errorf("invalid chat ID: %w", err)
+			next, ok := fallbackMode(mode)
+			if !isParseError(err) || !ok {
+				return nil, err
+			}
+
+			// The markup of the fallback mode can be longer, so the parts
+			// left to send are split again for it.
+			mode = next
+			parts = append(parts[:i:i], c.resplit(parts[i:], mode, i == 0 && len(o.Attachments) > 0)...)
+			i--
+			continue
+		}
+
+		if receipt == nil {
+			receipt = sent
+		} else {
+			receipt.Parts = append(receipt.Parts, sent.MessageID)
+		}
+		modes = append(modes, modeName(mode))
+		replyTo, _ = strconv.Atoi(sent.MessageID)
+	}
+
+	receipt.ParseMode = modeName(mode)
+	if modes[0] != receipt.ParseMode {
+		receipt.PartParseModes = modes
+	}
+	return receipt, nil
+}
+
+// split parses message and splits it into the parts to send. As with
+// formatter.Split, a block that cannot be split to fit a part, such as a
+// single huge table row, is sent as plain text cut to the limit.
+func (c *Client) split(message string, o messengers.SendOptions) []*formatter.Node {
+	opts := formatter.SplitOptions{
+		Limit:  formatter.TelegramMessageLimit,
+		Number: o.NumberParts,
+	}
+	// With attachments the first part is a caption, which has a lower limit.
+	if len(o.Attachments) > 0 {
+		opts.FirstLimit = formatter.TelegramCaptionLimit
+	}
+	return formatter.SplitDocument(formatter.Parse(message), c.renderer(c.parseMode), opts)
+}
+
+// resplit splits parts, split for another mode, again where they are too
+// long in mode. A part keeps its number, if any, on its last piece. caption
+// tells whether the first part is a caption.
+func (c *Client) resplit(parts []*formatter.Node, mode string, caption bool) []*formatter.Node {
+	var out []*formatter.Node
+	for i, part := range parts {
+		opts := formatter.SplitOptions{Limit: formatter.TelegramMessageLimit}
+		if i == 0 && caption {
+			opts.FirstLimit = formatter.TelegramCaptionLimit
+		}
+		out = append(out, formatter.SplitDocument(part, c.renderer(mode), opts)...)
+	}
+	return out
+}
+
+// Preview renders message the way SendMessage would send it in the given
+// parse mode, without a token or a connection. The fallback modes are only
+// used when Telegram rejects a message, so they do not show up here.
+func Preview(message, parseMode string, directory formatter.Directory, opts ...messengers.SendOption) (*messengers.Preview, error) {
+	mode, err := parseModeFor(parseMode)
+	if err != nil {
+		return nil, messengers.NewError(messengers.ErrorKindConfig, err)
+	}
+
+	c := &Client{parseMode: mode, directory: directory}
+	preview := &messengers.Preview{Platform: c.GetName(), ParseMode: modeName(mode)}
+	for _, part := range c.split(message, messengers.ApplySendOptions(opts)) {
+		text := c.render(part, mode)
+		p := messengers.PreviewPart{Text: text.text}
+		if len(text.entities) > 0 {
+			p.Fields = map[string]any{"entities": text.entities}
 		}
+		preview.Parts = append(preview.Parts, p)
 	}
+	return preview, nil
+}
+
+func (c *Client) sendText(chatID int64, text content, replyTo int) (*messengers.Receipt, error) {
+	msg := tgbotapi.NewMessage(chatID, text.text)
+	msg.ParseMode = text.parseMode
+	msg.Entities = text.entities
+	msg.ReplyToMessageID = replyTo
 
-	formattedMessage := formatter.ToTelegramMarkdown(message)
+	sent, err := c.bot.Send(msg)
+	if err != nil {
+		return nil, wrapError("send message to", err)
+	}
 
-	msg := tgbotapi.NewMessage(chatID, formattedMessage)
-	msg.ParseMode = "MarkdownV2"
+	return c.receipt(sent), nil
+}
 
-	_, err = c.bot.Send(msg)
+func (c *Client) EditMessage(chatIDStr, messageID, message string) (*messengers.Receipt, error) {
+	chatID, err := c.resolveChatID(chatIDStr)
 	if err != nil {
-		return fmt.Errorf("failed to send message to Telegram: %w", err)
+		return nil, err
+	}
+
+	id, err := parseMessageID(messageID)
+	if err != nil {
+		return nil, err
+	}
+
+	parts := c.split(message, messengers.SendOptions{})
+	if len(parts) > 1 {
+		return nil, messengers.NewError(messengers.ErrorKindInput, fmt.Errorf("message is too long to fit in one Telegram message"))
+	}
+
+	receipt, mode, err := c.withFallback(parts[0], c.parseMode, func(text content) (*messengers.Receipt, error) {
+		edit := tgbotapi.NewEditMessageText(chatID, id, text.text)
+		edit.ParseMode = text.parseMode
+		edit.Entities = text.entities
+
+		sent, err := c.bot.Send(edit)
+		if err != nil {
+			return nil, wrapError("edit message in", err)
+		}
+		return c.receipt(sent), nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	receipt.ParseMode = modeName(mode)
+	return receipt, nil
+}
+
+func (c *Client) DeleteMessage(chatIDStr, messageID string) error {
+	chatID, err := c.resolveChatID(chatIDStr)
+	if err != nil {
+		return err
+	}
+
+	id, err := parseMessageID(messageID)
+	if err != nil {
+		return err
+	}
+
+	if _, err := c.bot.Request(tgbotapi.NewDeleteMessage(chatID, id)); err != nil {
+		return wrapError("delete message in", err)
 	}
 
 	return nil
 }
 
+func (c *Client) resolveChatID(chatIDStr string) (int64, error) {
+	if chatIDStr == "" {
+		if c.defaultChatID == 0 {
+			return 0, messengers.NewError(messengers.ErrorKindInput, fmt.Errorf("chat ID is required"))
+		}
+		return c.defaultChatID, nil
+	}
+
+	chatID, err := strconv.ParseInt(chatIDStr, 10, 64)
+	if err != nil {
+		return 0, messengers.NewError(messengers.ErrorKindInput, fmt.Errorf("invalid chat ID: %w", err))
+	}
+	return chatID, nil
+}
+
+func parseMessageID(messageID string) (int, error) {
+	id, err := strconv.Atoi(messageID)
+	if err != nil {
+		return 0, messengers.NewError(messengers.ErrorKindInput, fmt.Errorf("invalid message ID: %w", err))
+	}
+	return id, nil
+}
+
+func (c *Client) receipt(msg tgbotapi.Message) *messengers.Receipt {
+	receipt := &messengers.Receipt{
+		Platform:  c.GetName(),
+		MessageID: strconv.Itoa(msg.MessageID),
+		Timestamp: msg.Time(),
+	}
+
+	if msg.Chat != nil {
+		receipt.Channel = strconv.FormatInt(msg.Chat.ID, 10)
+		receipt.Permalink = permalink(msg.Chat, msg.MessageID)
+	}
+
+	return receipt
+}
+
+// permalink returns the t.me link of a message. Links exist only for
+// messages in supergroups and channels: by username for public ones and by
+// internal ID for private ones. Private chats and basic groups have none,
+// even when the user or group has a username.
+func permalink(chat *tgbotapi.Chat, messageID int) string {
+	if !chat.IsSuperGroup() && !chat.IsChannel() {
+		return ""
+	}
+
+	if chat.UserName != "" {
+		return fmt.Sprintf("https://t.me/%s/%d", chat.UserName, messageID)
+	}
+
+	if id := strconv.FormatInt(chat.ID, 10); strings.HasPrefix(id, "-100") {
+		return fmt.Sprintf("https://t.me/c/%s/%d", strings.TrimPrefix(id, "-100"), messageID)
+	}
+
+	return ""
+}
+
 func (c *Client) GetName() string {
 	return "Telegram"
 }
@@ -0,0 +1,311 @@
+// Package lint checks messages against the rules of each messenger before
+// they are sent: link URLs, length limits, Telegram MarkdownV2 markup,
+// Discord embed limits and the Slack block limit.
+package lint
+
+import (
+	"CLIMultiChat/internal/formatter"
+	messengers "CLIMultiChat/internal/integrations"
+	"CLIMultiChat/internal/integrations/discord"
+	"CLIMultiChat/internal/integrations/slack"
+	"CLIMultiChat/internal/integrations/telegram"
+	"errors"
+	"fmt"
+	"net/url"
+	"strings"
+	"unicode/utf16"
+	"unicode/utf8"
+)
+
+// Severity tells whether an issue stops a message from being sent.
+type Severity string
+
+const (
+	// SeverityError is a problem the platform would reject or mangle.
+	SeverityError Severity = "error"
+	// SeverityWarning is something worth knowing that does not stop the
+	// send, such as a message being split into parts.
+	SeverityWarning Severity = "warning"
+)
+
+// Issue is a problem found in a message. Line and Column are 1-based and
+// count characters; they are zero when the issue has no position. Part is
+// zero when the position is in the message as written, and otherwise the
+// 1-based number of the rendered part it is in.
+type Issue struct {
+	Platform string
+	Severity Severity
+	Part     int
+	Line     int
+	Column   int
+	Message  string
+}
+
+// String formats the issue the way compilers do, for example
+// "Telegram, part 1, 3:14: error: unescaped "." (must be written as "\.")".
+func (i Issue) String() string {
+	var where []string
+	if i.Platform != "" {
+		where = append(where, i.Platform)
+	}
+	if i.Part > 0 {
+		where = append(where, fmt.Sprintf("part %d", i.Part))
+	}
+	if i.Line > 0 {
+		where = append(where, fmt.Sprintf("%d:%d", i.Line, i.Column))
+	}
+
+	prefix := ""
+	if len(where) > 0 {
+		prefix = strings.Join(where, ", ") + ": "
+	}
+	return prefix + string(i.Severity) + ": " + i.Message
+}
+
+// HasErrors reports whether any of issues is an error.
+func HasErrors(issues []Issue) bool {
+	for _, i := range issues {
+		if i.Severity == SeverityError {
+			return true
+		}
+	}
+	return false
+}
+
+// Settings are the platform settings that change how a message is rendered.
+type Settings struct {
+	SlackFormat       string
+	TelegramParseMode string
+}
+
+// Check checks message as it would be sent to each of platforms ("slack",
+// "telegram" or "discord") with opts. Issues in the message itself come
+// first, then those of each platform in the order given.
+func Check(message string, platforms []string, settings Settings, opts ...messengers.SendOption) []Issue {
+	o := messengers.ApplySendOptions(opts)
+
+	issues := checkLinks(message)
+	if o.Embed != nil && o.Embed.URL != "" {
+		if problem := checkURL(o.Embed.URL); problem != "" {
+			issues = append(issues, Issue{Severity: SeverityError, Message: "embed " + problem})
+		}
+	}
+
+	for _, platform := range platforms {
+		switch platform {
+		case "slack":
+			issues = append(issues, checkSlack(message, settings.SlackFormat, opts)...)
+		case "telegram":
+			issues = append(issues, checkTelegram(message, settings.TelegramParseMode, opts)...)
+		case "discord":
+			issues = append(issues, checkDiscord(message, o, opts)...)
+		}
+	}
+	return issues
+}
+
+// checkLinks reports link targets that are not absolute URLs of a scheme
+// the messengers link, at the position of the URL in message.
+func checkLinks(message string) []Issue {
+	var issues []Issue
+	from := 0
+	walk(formatter.Parse(message), func(n *formatter.Node) {
+		if n.Kind != formatter.KindLink {
+			return
+		}
+
+		offset := -1
+		if i := strings.Index(message[from:], n.URL); i >= 0 {
+			offset = from + i
+			from = offset + len(n.URL)
+		}
+
+		if problem := checkURL(n.URL); problem != "" {
+			issue := Issue{Severity: SeverityError, Message: problem}
+			if offset >= 0 {
+				issue.Line, issue.Column = position(message, offset)
+			}
+			issues = append(issues, issue)
+		}
+	})
+	return issues
+}
+
+// checkURL describes what is wrong with a link target, or returns "".
+func checkURL(raw string) string {
+	u, err := url.Parse(raw)
+	if err != nil {
+		return fmt.Sprintf("invalid link URL %q: %v", raw, errors.Unwrap(err))
+	}
+
+	switch strings.ToLower(u.Scheme) {
+	case "":
+		return fmt.Sprintf("link URL %q is not absolute", raw)
+	case "http", "https":
+		if u.Host == "" {
+			return fmt.Sprintf("link URL %q has no host", raw)
+		}
+	case "mailto", "tg":
+	default:
+		return fmt.Sprintf("link URL %q has unsupported scheme %q", raw, u.Scheme)
+	}
+	return ""
+}
+
+func checkSlack(message, format string, opts []messengers.SendOption) []Issue {
+	preview, err := slack.Preview(message, format, formatter.Directory{}, opts...)
+	if err != nil {
+		return []Issue{{Platform: "Slack", Severity: SeverityError, Message: err.Error()}}
+	}
+
+	if preview.ParseMode == slack.FormatBlocks {
+		total := 0
+		for _, m := range slack.RenderBlocks(message, formatter.SlackRenderer{}) {
+			total += len(m.Blocks)
+		}
+		if total > slack.MaxBlocks {
+			return []Issue{{
+				Platform: "Slack",
+				Severity: SeverityWarning,
+				Message:  fmt.Sprintf("message has %d blocks, more than the %d of one message; it will be sent as %d messages", total, slack.MaxBlocks, len(preview.Parts)),
+			}}
+		}
+		return nil
+	}
+
+	return append(lengthErrors(preview, formatter.SlackMessageLimit, formatter.SlackMessageLimit), splitWarning(preview, formatter.SlackMessageLimit)...)
+}
+
+func checkTelegram(message, parseMode string, opts []messengers.SendOption) []Issue {
+	preview, err := telegram.Preview(message, parseMode, formatter.Directory{}, opts...)
+	if err != nil {
+		return []Issue{{Platform: "Telegram", Severity: SeverityError, Message: err.Error()}}
+	}
+
+	var issues []Issue
+	if preview.ParseMode == "markdownv2" {
+		// A part Telegram cannot parse is sent again in the fallback mode,
+		// so invalid markup only costs the formatting.
+		severity, note := SeverityError, ""
+		if fallback, ok := telegram.Fallback(preview.ParseMode); ok {
+			severity, note = SeverityWarning, "; the part will be sent as "+fallback
+		}
+
+		for i, part := range preview.Parts {
+			for _, e := range ValidateMarkdownV2(part.Text) {
+				line, column := position(part.Text, e.Offset)
+				issues = append(issues, Issue{
+					Platform: "Telegram",
+					Severity: severity,
+					Part:     i + 1,
+					Line:     line,
+					Column:   column,
+					Message:  e.Message + note,
+				})
+			}
+		}
+	}
+
+	// With attachments the first part is a caption, which has a lower limit.
+	first := formatter.TelegramMessageLimit
+	if len(messengers.ApplySendOptions(opts).Attachments) > 0 {
+		first = formatter.TelegramCaptionLimit
+	}
+	issues = append(issues, lengthErrors(preview, first, formatter.TelegramMessageLimit)...)
+	return append(issues, splitWarning(preview, formatter.TelegramMessageLimit)...)
+}
+
+func checkDiscord(message string, o messengers.SendOptions, opts []messengers.SendOption) []Issue {
+	if o.Embed != nil {
+		err := discord.ValidateEmbed(discord.BuildEmbed(o.Embed, formatter.DiscordRenderer{}))
+		if err == nil {
+			return nil
+		}
+
+		errs := []error{err}
+		if joined, ok := err.(interface{ Unwrap() []error }); ok {
+			errs = joined.Unwrap()
+		}
+
+		var issues []Issue
+		for _, e := range errs {
+			issues = append(issues, Issue{Platform: "Discord", Severity: SeverityError, Message: e.Error()})
+		}
+		return issues
+	}
+
+	preview, err := discord.Preview(message, formatter.Directory{}, opts...)
+	if err != nil {
+		return []Issue{{Platform: "Discord", Severity: SeverityError, Message: err.Error()}}
+	}
+	return append(lengthErrors(preview, formatter.DiscordMessageLimit, formatter.DiscordMessageLimit), splitWarning(preview, formatter.DiscordMessageLimit)...)
+}
+
+// lengthErrors reports the parts of preview still longer than the limit of
+// the platform after splitting, which it would reject, at the position of
+// the first character over the limit. first is the limit of the first part.
+func lengthErrors(preview *messengers.Preview, first, limit int) []Issue {
+	var issues []Issue
+	for i, part := range preview.Parts {
+		partLimit := limit
+		if i == 0 {
+			partLimit = first
+		}
+
+		offset, length := -1, 0
+		for j, r := range part.Text {
+			length += utf16.RuneLen(r)
+			if length > partLimit && offset < 0 {
+				offset = j
+			}
+		}
+		if offset < 0 {
+			continue
+		}
+
+		line, column := position(part.Text, offset)
+		issues = append(issues, Issue{
+			Platform: preview.Platform,
+			Severity: SeverityError,
+			Part:     i + 1,
+			Line:     line,
+			Column:   column,
+			Message:  fmt.Sprintf("part is %d characters long, over the limit of %d", length, partLimit),
+		})
+	}
+	return issues
+}
+
+// splitWarning warns that a message over the limit of a platform will be
+// sent in several parts.
+func splitWarning(preview *messengers.Preview, limit int) []Issue {
+	if len(preview.Parts) < 2 {
+		return nil
+	}
+
+	length := 0
+	for _, part := range preview.Parts {
+		length += utf8.RuneCountInString(part.Text)
+	}
+	return []Issue{{
+		Platform: preview.Platform,
+		Severity: SeverityWarning,
+		Message:  fmt.Sprintf("message is %d characters long, over the limit of %d; it will be sent in %d parts", length, limit, len(preview.Parts)),
+	}}
+}
+
+// walk calls visit for n and every node below it, in document order.
+func walk(n *formatter.Node, visit func(*formatter.Node)) {
+	visit(n)
+	for _, child := range n.Children {
+		walk(child, visit)
+	}
+}
+
+// position converts a byte offset in text to a 1-based line and column.
+func position(text string, offset int) (line, column int) {
+	before := text[:offset]
+	line = strings.Count(before, "\n") + 1
+	column = utf8.RuneCountInString(before[strings.LastIndex(before, "\n")+1:]) + 1
+	return line, column
+}
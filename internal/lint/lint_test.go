@@ -0,0 +1,154 @@
+package lint
+
+import (
+	messengers "CLIMultiChat/internal/integrations"
+	"strings"
+	"testing"
+)
+
+func TestCheckLinks(t *testing.T) {
+	tests := []struct {
+		name    string
+		message string
+		// want holds the line, column and a part of the message of each
+		// issue.
+		want []Issue
+	}{
+		{name: "valid", message: "[site](https://example.com) <mailto:a@example.com> [chat](tg://resolve?domain=x)"},
+		{
+			name:    "relative",
+			message: "see [docs](docs/index.html)",
+			want:    []Issue{{Line: 1, Column: 12, Message: "is not absolute"}},
+		},
+		{
+			name:    "no host",
+			message: "first line\n[x](https://)",
+			want:    []Issue{{Line: 2, Column: 5, Message: "has no host"}},
+		},
+		{
+			name:    "scheme",
+			message: "[x](ftp://example.com)",
+			want:    []Issue{{Line: 1, Column: 5, Message: `unsupported scheme "ftp"`}},
+		},
+		{
+			name:    "same URL twice",
+			message: "[a](b.html) and [c](b.html)",
+			want: []Issue{
+				{Line: 1, Column: 5, Message: "is not absolute"},
+				{Line: 1, Column: 21, Message: "is not absolute"},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := checkLinks(tt.message)
+			if len(got) != len(tt.want) {
+				t.Fatalf("checkLinks(%q) = %v, want %d issues", tt.message, got, len(tt.want))
+			}
+			for i, want := range tt.want {
+				if got[i].Line != want.Line || got[i].Column != want.Column || !strings.Contains(got[i].Message, want.Message) {
+					t.Errorf("issue %d = %v, want %d:%d %q", i, got[i], want.Line, want.Column, want.Message)
+				}
+			}
+		})
+	}
+}
+
+func TestLengthErrors(t *testing.T) {
+	preview := &messengers.Preview{
+		Platform: "Telegram",
+		Parts: []messengers.PreviewPart{
+			{Text: strings.Repeat("a", 10)},
+			{Text: "line\n" + strings.Repeat("b", 20)},
+			{Text: strings.Repeat("c", 15)},
+		},
+	}
+
+	got := lengthErrors(preview, 8, 16)
+	want := []Issue{
+		{Platform: "Telegram", Severity: SeverityError, Part: 1, Line: 1, Column: 9},
+		{Platform: "Telegram", Severity: SeverityError, Part: 2, Line: 2, Column: 12},
+	}
+	if len(got) != len(want) {
+		t.Fatalf("lengthErrors = %v, want %d issues", got, len(want))
+	}
+	for i := range want {
+		got[i].Message = ""
+		if got[i] != want[i] {
+			t.Errorf("issue %d = %+v, want %+v", i, got[i], want[i])
+		}
+	}
+}
+
+func TestCheck(t *testing.T) {
+	tests := []struct {
+		name      string
+		message   string
+		platforms []string
+		settings  Settings
+		opts      []messengers.SendOption
+		errors    bool
+		warnings  bool
+	}{
+		{
+			name:      "clean",
+			message:   "Deploy **done**: [build](https://ci.example.com/1) 1.2.3",
+			platforms: []string{"slack", "telegram", "discord"},
+			settings:  Settings{TelegramParseMode: "markdownv2"},
+		},
+		{
+			name:      "bad link",
+			message:   "[build](ci/1)",
+			platforms: []string{"slack"},
+			errors:    true,
+		},
+		{
+			name:      "long message",
+			message:   strings.Repeat("A sentence of text. ", 200),
+			platforms: []string{"discord"},
+			warnings:  true,
+		},
+		{
+			name:      "empty embed field",
+			message:   "body",
+			platforms: []string{"discord"},
+			opts: []messengers.SendOption{messengers.WithEmbed(&messengers.Embed{
+				Title:  "Deploy",
+				Fields: []messengers.EmbedField{{Name: "Env", Value: " "}},
+			})},
+			errors: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			issues := Check(tt.message, tt.platforms, tt.settings, tt.opts...)
+
+			warnings := false
+			for _, issue := range issues {
+				warnings = warnings || issue.Severity == SeverityWarning
+			}
+			if HasErrors(issues) != tt.errors || warnings != tt.warnings {
+				t.Errorf("Check(%q) = %v, want errors %v and warnings %v", tt.message, issues, tt.errors, tt.warnings)
+			}
+		})
+	}
+}
+
+func TestIssueString(t *testing.T) {
+	tests := []struct {
+		issue Issue
+		want  string
+	}{
+		{Issue{Severity: SeverityError, Message: "bad"}, "error: bad"},
+		{Issue{Platform: "Slack", Severity: SeverityWarning, Message: "long"}, "Slack: warning: long"},
+		{Issue{Platform: "Telegram", Severity: SeverityError, Part: 2, Line: 3, Column: 14, Message: "x"}, "Telegram, part 2, 3:14: error: x"},
+	}
+
+	for _, tt := range tests {
+		if got := tt.issue.String(); got != tt.want {
+			t.Errorf("String() = %q, want %q", got, tt.want)
+		}
+	}
+}
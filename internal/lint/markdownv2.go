@@ -0,0 +1,135 @@
+package lint
+
+import (
+	"fmt"
+	"strings"
+	"unicode/utf8"
+)
+
+// TextError is a problem at a byte offset of a text.
+type TextError struct {
+	Offset  int
+	Message string
+}
+
+// markdownV2Reserved are the characters that must be escaped with a
+// backslash in MarkdownV2 text when they are not markup.
+const markdownV2Reserved = "_*[]()~`>#+-=|{}.!"
+
+// ValidateMarkdownV2 checks text in Telegram's MarkdownV2: every entity must
+// be closed and properly nested, code must be closed, links must have a URL
+// in parentheses and reserved characters outside markup must be escaped.
+func ValidateMarkdownV2(text string) []TextError {
+	var errs []TextError
+	fail := func(offset int, format string, args ...any) {
+		errs = append(errs, TextError{Offset: offset, Message: fmt.Sprintf(format, args...)})
+	}
+
+	type entity struct {
+		marker string
+		offset int
+	}
+	var open []entity
+
+	// toggle closes the entity marker opens or opens a new one.
+	toggle := func(marker string, offset int) {
+		for i := len(open) - 1; i >= 0; i-- {
+			if open[i].marker != marker {
+				continue
+			}
+			if i != len(open)-1 {
+				inner := open[len(open)-1]
+				fail(offset, "%q closes before %q opened at %s is closed", marker, inner.marker, lineColumn(text, inner.offset))
+			}
+			open = open[:i]
+			return
+		}
+		open = append(open, entity{marker, offset})
+	}
+
+	lineStart := true
+	for i := 0; i < len(text); {
+		c := text[i]
+		next := i + 1
+
+		switch {
+		case c == '\\':
+			if next == len(text) {
+				fail(i, `"\" at the end of the text escapes nothing`)
+				break
+			}
+			_, size := utf8.DecodeRuneInString(text[next:])
+			next += size
+		case c == '`':
+			fence := "`"
+			if strings.HasPrefix(text[i:], "```") {
+				fence = "```"
+			}
+			end := closing(text, i+len(fence), fence)
+			if end < 0 {
+				fail(i, "code opened with %q is not closed", fence)
+				next = len(text)
+				break
+			}
+			next = end + len(fence)
+		case c == '[':
+			open = append(open, entity{"[", i})
+		case c == ']':
+			if len(open) == 0 || open[len(open)-1].marker != "[" {
+				fail(i, `unexpected "]" (must be written as "\]")`)
+				break
+			}
+			open = open[:len(open)-1]
+			if next == len(text) || text[next] != '(' {
+				fail(i, `link text must be followed by its URL in parentheses`)
+				break
+			}
+			end := closing(text, next+1, ")")
+			if end < 0 {
+				fail(next, "link URL is not closed")
+				next = len(text)
+				break
+			}
+			next = end + 1
+		case c == '_' && strings.HasPrefix(text[i:], "__"):
+			toggle("__", i)
+			next = i + 2
+		case c == '|' && strings.HasPrefix(text[i:], "||"):
+			toggle("||", i)
+			next = i + 2
+		case c == '*' || c == '_' || c == '~':
+			toggle(string(c), i)
+		case c == '>' && lineStart:
+			// A quote.
+		case strings.IndexByte(markdownV2Reserved, c) >= 0:
+			fail(i, `unescaped %q (must be written as "\%c")`, string(c), c)
+		}
+
+		lineStart = c == '\n'
+		i = next
+	}
+
+	for _, e := range open {
+		fail(e.offset, "%q is not closed", e.marker)
+	}
+	return errs
+}
+
+// closing returns the offset of the first unescaped delim in text at or
+// after from, or -1.
+func closing(text string, from int, delim string) int {
+	for i := from; i < len(text); i++ {
+		switch {
+		case text[i] == '\\':
+			i++
+		case strings.HasPrefix(text[i:], delim):
+			return i
+		}
+	}
+	return -1
+}
+
+func lineColumn(text string, offset int) string {
+	line, column := position(text, offset)
+	return fmt.Sprintf("%d:%d", line, column)
+}
@@ -0,0 +1,121 @@
+package lint
+
+import (
+	"CLIMultiChat/internal/formatter"
+	"strings"
+	"testing"
+)
+
+func TestValidateMarkdownV2(t *testing.T) {
+	tests := []struct {
+		name string
+		text string
+		// want holds the offset and a part of the message of each error.
+		want []TextError
+	}{
+		{name: "plain", text: "hello world"},
+		{name: "escaped", text: `1\. done\! \(really\)`},
+		{name: "bold", text: "*bold* text"},
+		{name: "nested", text: "*bold _italic_ bold*"},
+		{name: "underline and spoiler", text: "__under__ ||secret||"},
+		{name: "link", text: `[label](https://example.com/a\)b)`},
+		{name: "code", text: "`a.b*c`"},
+		{name: "pre", text: "```go\nx := 1 + 2\n```"},
+		{name: "quote", text: ">quoted\n>lines"},
+		{
+			name: "unescaped dot",
+			text: "end.",
+			want: []TextError{{3, `unescaped "."`}},
+		},
+		{
+			name: "unescaped in text after markup",
+			text: "*bold* a-b",
+			want: []TextError{{8, `unescaped "-"`}},
+		},
+		{
+			name: "unclosed bold",
+			text: "*bold",
+			want: []TextError{{0, `"*" is not closed`}},
+		},
+		{
+			name: "crossed entities",
+			text: "*a _b* c_",
+			want: []TextError{{5, `"*" closes before "_"`}, {8, `"_" is not closed`}},
+		},
+		{
+			name: "unclosed code",
+			text: "`code",
+			want: []TextError{{0, "code opened"}},
+		},
+		{
+			name: "unclosed pre",
+			text: "```\ncode",
+			want: []TextError{{0, "code opened"}},
+		},
+		{
+			name: "link without URL",
+			text: "[label] x",
+			want: []TextError{{6, "followed by its URL"}},
+		},
+		{
+			name: "unclosed link URL",
+			text: "[label](https://example.com",
+			want: []TextError{{7, "link URL is not closed"}},
+		},
+		{
+			name: "stray bracket",
+			text: "a]",
+			want: []TextError{{1, `unexpected "]"`}},
+		},
+		{
+			name: "trailing backslash",
+			text: `a\`,
+			want: []TextError{{1, "escapes nothing"}},
+		},
+		{
+			name: "quote marker inside a line",
+			text: "a > b",
+			want: []TextError{{2, `unescaped ">"`}},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := ValidateMarkdownV2(tt.text)
+			if len(got) != len(tt.want) {
+				t.Fatalf("ValidateMarkdownV2(%q) = %v, want %d errors", tt.text, got, len(tt.want))
+			}
+			for i, want := range tt.want {
+				if got[i].Offset != want.Offset || !strings.Contains(got[i].Message, want.Message) {
+					t.Errorf("error %d = %d %q, want %d %q", i, got[i].Offset, got[i].Message, want.Offset, want.Message)
+				}
+			}
+		})
+	}
+}
+
+func TestRenderedMarkdownV2IsValid(t *testing.T) {
+	// Whatever the Markdown, the MarkdownV2 renderer must produce text
+	// Telegram can parse, also once it is split.
+	tests := []string{
+		"Plain text with reserved characters: . ! - + = # | { } ( ) > ~",
+		"**bold**, *italic*, ~~struck~~, ||spoiler|| and `code . with * marks`",
+		"[a link](https://example.com/path_(x)?a=1&b=2) and <https://example.com>",
+		"# Heading with **bold**\n\nParagraph 1.5 > 1",
+		"- item one\n- item *two*\n  1. nested\n  2. list",
+		"> quoted **text**\n> more",
+		"- item\n\n  > quoted\n  >\n  > more\n- > quote first\n  1. nested\n\n     > deeper",
+		"```go\nfmt.Println(`a\\b`)\n```",
+		"| a | b |\n|---|---|\n| 1.5 | x_y |",
+		"@here, @user:alice and :tada: :white_check_mark:",
+		"Intro **" + strings.Repeat("bold words. ", 60) + "end** and more text.",
+	}
+
+	for _, text := range tests {
+		for i, part := range formatter.Split(text, formatter.TelegramRenderer{}, formatter.SplitOptions{Limit: 200}) {
+			for _, e := range ValidateMarkdownV2(part) {
+				t.Errorf("%q, part %d %q: %s at %s", text, i+1, part, e.Message, lineColumn(part, e.Offset))
+			}
+		}
+	}
+}